@@ -80,14 +80,48 @@ func (c *Client) ListPools(providers, names []string) ([]params.StoragePool, err
 	return results.Results[0].Result, nil
 }
 
-// CreatePool creates pool with specified parameters.
-func (c *Client) CreatePool(pname, provider string, attrs map[string]interface{}) error {
+// CreatePool creates pool with specified parameters, and returns the
+// pool as it was created, including any default attributes supplied
+// by the storage provider.
+func (c *Client) CreatePool(pname, provider string, attrs map[string]interface{}) (params.StoragePool, error) {
 	args := params.StoragePool{
 		Name:     pname,
 		Provider: provider,
 		Attrs:    attrs,
 	}
-	return c.facade.FacadeCall("CreatePool", args, nil)
+	if c.BestAPIVersion() < 5 {
+		// Older controllers neither apply provider defaults
+		// nor return the created pool, so the pool is as
+		// requested.
+		if err := c.facade.FacadeCall("CreatePool", args, nil); err != nil {
+			return params.StoragePool{}, errors.Trace(err)
+		}
+		return args, nil
+	}
+	var result params.StoragePool
+	if err := c.facade.FacadeCall("CreatePool", args, &result); err != nil {
+		return params.StoragePool{}, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// ValidatePool validates a pool with specified parameters without
+// creating it, and returns the pool as it would be created, including
+// any default attributes supplied by the storage provider.
+func (c *Client) ValidatePool(pname, provider string, attrs map[string]interface{}) (params.StoragePool, error) {
+	if c.BestAPIVersion() < 5 {
+		return params.StoragePool{}, errors.Errorf("this juju controller does not support validating storage pools")
+	}
+	args := params.StoragePool{
+		Name:     pname,
+		Provider: provider,
+		Attrs:    attrs,
+	}
+	var result params.StoragePool
+	if err := c.facade.FacadeCall("ValidatePool", args, &result); err != nil {
+		return params.StoragePool{}, errors.Trace(err)
+	}
+	return result, nil
 }
 
 // ListVolumes lists volumes for desired machines.
@@ -265,9 +265,51 @@ func (s *storageMockSuite) TestCreatePool(c *gc.C) {
 			return nil
 		})
 	storageClient := storage.NewClient(apiCaller)
-	err := storageClient.CreatePool(poolName, poolType, poolConfig)
+	pool, err := storageClient.CreatePool(poolName, poolType, poolConfig)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(called, jc.IsTrue)
+	c.Assert(pool, jc.DeepEquals, params.StoragePool{
+		Name:     poolName,
+		Provider: poolType,
+		Attrs:    poolConfig,
+	})
+}
+
+func (s *storageMockSuite) TestCreatePoolV5(c *gc.C) {
+	apiCaller := basetesting.BestVersionCaller{
+		APICallerFunc: basetesting.APICallerFunc(
+			func(objType string,
+				version int,
+				id, request string,
+				a, result interface{},
+			) error {
+				c.Check(objType, gc.Equals, "Storage")
+				c.Check(id, gc.Equals, "")
+				c.Check(request, gc.Equals, "CreatePool")
+				c.Check(a, jc.DeepEquals, params.StoragePool{
+					Name:     "poolName",
+					Provider: "poolType",
+					Attrs:    map[string]interface{}{"test": "one"},
+				})
+				c.Assert(result, gc.FitsTypeOf, &params.StoragePool{})
+				*(result.(*params.StoragePool)) = params.StoragePool{
+					Name:     "poolName",
+					Provider: "poolType",
+					Attrs:    map[string]interface{}{"test": "one", "default": "two"},
+				}
+				return nil
+			},
+		),
+		BestVersion: 5,
+	}
+	storageClient := storage.NewClient(apiCaller)
+	pool, err := storageClient.CreatePool("poolName", "poolType", map[string]interface{}{"test": "one"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pool, jc.DeepEquals, params.StoragePool{
+		Name:     "poolName",
+		Provider: "poolType",
+		Attrs:    map[string]interface{}{"test": "one", "default": "two"},
+	})
 }
 
 func (s *storageMockSuite) TestCreatePoolFacadeCallError(c *gc.C) {
@@ -285,10 +327,80 @@ func (s *storageMockSuite) TestCreatePoolFacadeCallError(c *gc.C) {
 			return errors.New(msg)
 		})
 	storageClient := storage.NewClient(apiCaller)
-	err := storageClient.CreatePool("", "", nil)
+	_, err := storageClient.CreatePool("", "", nil)
 	c.Assert(errors.Cause(err), gc.ErrorMatches, msg)
 }
 
+func (s *storageMockSuite) TestValidatePool(c *gc.C) {
+	apiCaller := basetesting.BestVersionCaller{
+		APICallerFunc: basetesting.APICallerFunc(
+			func(objType string,
+				version int,
+				id, request string,
+				a, result interface{},
+			) error {
+				c.Check(objType, gc.Equals, "Storage")
+				c.Check(id, gc.Equals, "")
+				c.Check(request, gc.Equals, "ValidatePool")
+				c.Check(a, jc.DeepEquals, params.StoragePool{
+					Name:     "poolName",
+					Provider: "poolType",
+					Attrs:    map[string]interface{}{"test": "one"},
+				})
+				c.Assert(result, gc.FitsTypeOf, &params.StoragePool{})
+				*(result.(*params.StoragePool)) = params.StoragePool{
+					Name:     "poolName",
+					Provider: "poolType",
+					Attrs:    map[string]interface{}{"test": "one", "default": "two"},
+				}
+				return nil
+			},
+		),
+		BestVersion: 5,
+	}
+	storageClient := storage.NewClient(apiCaller)
+	pool, err := storageClient.ValidatePool("poolName", "poolType", map[string]interface{}{"test": "one"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pool.Attrs, jc.DeepEquals, map[string]interface{}{"test": "one", "default": "two"})
+}
+
+func (s *storageMockSuite) TestValidatePoolFacadeCallError(c *gc.C) {
+	apiCaller := basetesting.BestVersionCaller{
+		APICallerFunc: basetesting.APICallerFunc(
+			func(objType string,
+				version int,
+				id, request string,
+				a, result interface{},
+			) error {
+				return errors.New("invalid pool")
+			},
+		),
+		BestVersion: 5,
+	}
+	storageClient := storage.NewClient(apiCaller)
+	_, err := storageClient.ValidatePool("poolName", "poolType", nil)
+	c.Assert(errors.Cause(err), gc.ErrorMatches, "invalid pool")
+}
+
+func (s *storageMockSuite) TestValidatePoolNotSupported(c *gc.C) {
+	apiCaller := basetesting.BestVersionCaller{
+		APICallerFunc: basetesting.APICallerFunc(
+			func(objType string,
+				version int,
+				id, request string,
+				a, result interface{},
+			) error {
+				c.Fatalf("unexpected call to %s", request)
+				return nil
+			},
+		),
+		BestVersion: 4,
+	}
+	storageClient := storage.NewClient(apiCaller)
+	_, err := storageClient.ValidatePool("poolName", "poolType", nil)
+	c.Assert(err, gc.ErrorMatches, "this juju controller does not support validating storage pools")
+}
+
 func (s *storageMockSuite) TestListVolumes(c *gc.C) {
 	var called bool
 	machines := []string{"0", "1"}
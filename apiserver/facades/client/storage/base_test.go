@@ -29,6 +29,7 @@ type baseStorageSuite struct {
 	api             *storage.APIv4
 	apiCaas         *storage.APIv4
 	apiv3           *storage.APIv3
+	apiv5           *storage.APIv5
 	storageAccessor *mockStorageAccessor
 	state           *mockState
 
@@ -73,6 +74,8 @@ func (s *baseStorageSuite) SetUpTest(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	s.apiv3, err = storage.NewAPIv3(s.state, state.ModelTypeIAAS, s.storageAccessor, s.registry, s.poolManager, s.resources, s.authorizer, s.callContext)
 	c.Assert(err, jc.ErrorIsNil)
+	s.apiv5, err = storage.NewAPIv5(s.state, state.ModelTypeIAAS, s.storageAccessor, s.registry, s.poolManager, s.resources, s.authorizer, s.callContext)
+	c.Assert(err, jc.ErrorIsNil)
 }
 
 // TODO(axw) get rid of assertCalls, use stub directly everywhere.
@@ -344,6 +347,9 @@ func (s *baseStorageSuite) constructPoolManager() *mockPoolManager {
 			s.pools[name] = pool
 			return pool, err
 		},
+		validatePool: func(name string, providerType jujustorage.ProviderType, attrs map[string]interface{}) (*jujustorage.Config, error) {
+			return jujustorage.NewConfig(name, providerType, attrs)
+		},
 		deletePool: func(name string) error {
 			delete(s.pools, name)
 			return nil
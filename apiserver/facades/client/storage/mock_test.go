@@ -16,10 +16,11 @@ import (
 )
 
 type mockPoolManager struct {
-	getPool    func(name string) (*jujustorage.Config, error)
-	createPool func(name string, providerType jujustorage.ProviderType, attrs map[string]interface{}) (*jujustorage.Config, error)
-	deletePool func(name string) error
-	listPools  func() ([]*jujustorage.Config, error)
+	getPool      func(name string) (*jujustorage.Config, error)
+	createPool   func(name string, providerType jujustorage.ProviderType, attrs map[string]interface{}) (*jujustorage.Config, error)
+	validatePool func(name string, providerType jujustorage.ProviderType, attrs map[string]interface{}) (*jujustorage.Config, error)
+	deletePool   func(name string) error
+	listPools    func() ([]*jujustorage.Config, error)
 }
 
 func (m *mockPoolManager) Get(name string) (*jujustorage.Config, error) {
@@ -30,6 +31,10 @@ func (m *mockPoolManager) Create(name string, providerType jujustorage.ProviderT
 	return m.createPool(name, providerType, attrs)
 }
 
+func (m *mockPoolManager) Validate(name string, providerType jujustorage.ProviderType, attrs map[string]interface{}) (*jujustorage.Config, error) {
+	return m.validatePool(name, providerType, attrs)
+}
+
 func (m *mockPoolManager) Delete(name string) error {
 	return m.deletePool(name)
 }
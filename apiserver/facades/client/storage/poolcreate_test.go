@@ -48,3 +48,63 @@ func (s *poolCreateSuite) TestCreatePoolError(c *gc.C) {
 	err := s.api.CreatePool(params.StoragePool{})
 	c.Assert(errors.Cause(err), gc.ErrorMatches, msg)
 }
+
+func (s *poolCreateSuite) TestCreatePoolV5(c *gc.C) {
+	s.baseStorageSuite.poolManager.createPool = func(name string, providerType jujustorage.ProviderType, attrs map[string]interface{}) (*jujustorage.Config, error) {
+		c.Assert(attrs, jc.DeepEquals, map[string]interface{}{"foo": "bar"})
+		return jujustorage.NewConfig(name, providerType, map[string]interface{}{
+			"foo": "bar", "default": "value",
+		})
+	}
+
+	pool, err := s.apiv5.CreatePool(params.StoragePool{
+		Name:     "pname",
+		Provider: string(provider.LoopProviderType),
+		Attrs:    map[string]interface{}{"foo": "bar"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pool, jc.DeepEquals, params.StoragePool{
+		Name:     "pname",
+		Provider: string(provider.LoopProviderType),
+		Attrs:    map[string]interface{}{"foo": "bar", "default": "value"},
+	})
+}
+
+func (s *poolCreateSuite) TestCreatePoolV5Error(c *gc.C) {
+	s.baseStorageSuite.poolManager.createPool = func(name string, providerType jujustorage.ProviderType, attrs map[string]interface{}) (*jujustorage.Config, error) {
+		return nil, errors.New("as expected")
+	}
+
+	_, err := s.apiv5.CreatePool(params.StoragePool{})
+	c.Assert(errors.Cause(err), gc.ErrorMatches, "as expected")
+}
+
+func (s *poolCreateSuite) TestValidatePool(c *gc.C) {
+	s.baseStorageSuite.poolManager.validatePool = func(name string, providerType jujustorage.ProviderType, attrs map[string]interface{}) (*jujustorage.Config, error) {
+		return jujustorage.NewConfig(name, providerType, map[string]interface{}{
+			"foo": "bar", "default": "value",
+		})
+	}
+
+	pool, err := s.apiv5.ValidatePool(params.StoragePool{
+		Name:     "pname",
+		Provider: string(provider.LoopProviderType),
+		Attrs:    map[string]interface{}{"foo": "bar"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pool, jc.DeepEquals, params.StoragePool{
+		Name:     "pname",
+		Provider: string(provider.LoopProviderType),
+		Attrs:    map[string]interface{}{"foo": "bar", "default": "value"},
+	})
+	c.Assert(s.pools, gc.HasLen, 0)
+}
+
+func (s *poolCreateSuite) TestValidatePoolError(c *gc.C) {
+	s.baseStorageSuite.poolManager.validatePool = func(name string, providerType jujustorage.ProviderType, attrs map[string]interface{}) (*jujustorage.Config, error) {
+		return nil, errors.New("invalid pool")
+	}
+
+	_, err := s.apiv5.ValidatePool(params.StoragePool{})
+	c.Assert(err, gc.ErrorMatches, "invalid pool")
+}
@@ -21,6 +21,19 @@ import (
 // to change any part of it so that it were no longer *obviously* and
 // *trivially* correct, you would be Doing It Wrong.
 
+// NewFacadeV5 provides the signature required for facade registration.
+func NewFacadeV5(
+	st *state.State,
+	resources facade.Resources,
+	authorizer facade.Authorizer,
+) (*APIv5, error) {
+	v4, err := NewFacadeV4(st, resources, authorizer)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &APIv5{v4}, nil
+}
+
 // NewFacadeV4 provides the signature required for facade registration.
 func NewFacadeV4(
 	st *state.State,
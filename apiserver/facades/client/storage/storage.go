@@ -39,6 +39,29 @@ type APIv4 struct {
 	*APIv3
 }
 
+// APIv5 implements the storage v5 API.
+type APIv5 struct {
+	*APIv4
+}
+
+// NewAPIv5 returns a new storage v5 API facade.
+func NewAPIv5(
+	backend backend,
+	modelType state.ModelType,
+	storageAccess storageAccess,
+	registry storage.ProviderRegistry,
+	pm poolmanager.PoolManager,
+	resources facade.Resources,
+	authorizer facade.Authorizer,
+	callContext context.ProviderCallContext,
+) (*APIv5, error) {
+	apiv4, err := NewAPIv4(backend, modelType, storageAccess, registry, pm, resources, authorizer, callContext)
+	if err != nil {
+		return nil, err
+	}
+	return &APIv5{apiv4}, nil
+}
+
 // NewAPIv4 returns a new storage v4 API facade.
 func NewAPIv4(
 	backend backend,
@@ -382,11 +405,7 @@ func filterPools(
 	all := make([]params.StoragePool, 0, len(pools))
 	for _, p := range pools {
 		if matches(p.Name(), string(p.Provider())) {
-			all = append(all, params.StoragePool{
-				Name:     p.Name(),
-				Provider: string(p.Provider()),
-				Attrs:    p.Attrs(),
-			})
+			all = append(all, storagePoolFromConfig(p))
 		}
 	}
 	return all
@@ -430,6 +449,41 @@ func (a *APIv3) CreatePool(p params.StoragePool) error {
 	return err
 }
 
+// CreatePool creates a new pool with specified parameters, and returns
+// the pool as it was saved, including any provider default attributes.
+func (a *APIv5) CreatePool(p params.StoragePool) (params.StoragePool, error) {
+	cfg, err := a.poolManager.Create(
+		p.Name,
+		storage.ProviderType(p.Provider),
+		p.Attrs)
+	if err != nil {
+		return params.StoragePool{}, err
+	}
+	return storagePoolFromConfig(cfg), nil
+}
+
+// ValidatePool validates a pool with specified parameters without
+// creating it, and returns the pool as it would be saved, including
+// any provider default attributes.
+func (a *APIv5) ValidatePool(p params.StoragePool) (params.StoragePool, error) {
+	cfg, err := a.poolManager.Validate(
+		p.Name,
+		storage.ProviderType(p.Provider),
+		p.Attrs)
+	if err != nil {
+		return params.StoragePool{}, err
+	}
+	return storagePoolFromConfig(cfg), nil
+}
+
+func storagePoolFromConfig(cfg *storage.Config) params.StoragePool {
+	return params.StoragePool{
+		Name:     cfg.Name(),
+		Provider: string(cfg.Provider()),
+		Attrs:    cfg.Attrs(),
+	}
+}
+
 // ListVolumes lists volumes with the given filters. Each filter produces
 // an independent list of volumes, or an error if the filter is invalid
 // or the volumes could not be listed.
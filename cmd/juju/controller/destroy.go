@@ -632,7 +632,7 @@ func (c *destroyCommandBase) credentialAPIForControllerModel() (CredentialAPI, e
 
 type newCredentialAPIFunc func() (CredentialAPI, error)
 
-func cloudCallContext(newAPIFunc newCredentialAPIFunc) context.ProviderCallContext {
+func cloudCallContext(newAPIFunc newCredentialAPIFunc) *context.CloudCallContext {
 	callCtx := context.NewCloudCallContext()
 	callCtx.InvalidateCredentialFunc = func(reason string) error {
 		api, err := newAPIFunc()
@@ -18,6 +18,7 @@ import (
 	"github.com/juju/juju/cmd/modelcmd"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/environs/context"
 )
 
 const killDoc = `
@@ -36,6 +37,12 @@ models do not stop cleanly, there is a default five minute timeout. If no change
 in the model state occurs for the duration of this timeout, the command will
 stop watching and destroy the models directly through the cloud provider.
 
+Destroying models directly through the cloud provider waits for the
+provider to delete their resources. With --direct-destroy-timeout, the
+command stops waiting once the timeout has passed; resources that the
+provider could not delete in time are reported, and running the command
+again finishes deleting them.
+
 See also:
     destroy-controller
     unregister
@@ -63,8 +70,9 @@ func wrapKillCommand(kill *killCommand) modelcmd.Command {
 type killCommand struct {
 	destroyCommandBase
 
-	clock   clock.Clock
-	timeout time.Duration
+	clock                clock.Clock
+	timeout              time.Duration
+	directDestroyTimeout time.Duration
 }
 
 // SetFlags implements Command.SetFlags.
@@ -72,6 +80,7 @@ func (c *killCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.destroyCommandBase.SetFlags(f)
 	f.Var(newDurationValue(time.Minute*5, &c.timeout), "t", "Timeout before direct destruction")
 	f.Var(newDurationValue(time.Minute*5, &c.timeout), "timeout", "")
+	f.Var(newDurationValue(0, &c.directDestroyTimeout), "direct-destroy-timeout", "Timeout for direct destruction through the cloud provider (0 for no limit)")
 }
 
 // Info implements Command.Info.
@@ -120,12 +129,11 @@ func (c *killCommand) Run(ctx *cmd.Context) error {
 	if err != nil {
 		return errors.Annotate(err, "getting controller environ")
 	}
-	cloudCallCtx := cloudCallContext(c.controllerCredentialAPIFunc)
 	// If we were unable to connect to the API, just destroy the controller through
 	// the environs interface.
 	if api == nil {
 		ctx.Infof("Unable to connect to the API server, destroying through provider")
-		return c.environsDestroy(controllerName, controllerEnviron, cloudCallCtx, store)
+		return c.environsDestroy(controllerName, controllerEnviron, c.directDestroyContext(c.controllerCredentialAPIFunc), store)
 	}
 
 	// Attempt to destroy the controller and all models and storage.
@@ -136,7 +144,7 @@ func (c *killCommand) Run(ctx *cmd.Context) error {
 	})
 	if err != nil {
 		ctx.Infof("Unable to destroy controller through the API: %s\nDestroying through provider", err)
-		return c.environsDestroy(controllerName, controllerEnviron, cloudCallCtx, store)
+		return c.environsDestroy(controllerName, controllerEnviron, c.directDestroyContext(c.controllerCredentialAPIFunc), store)
 	}
 
 	ctx.Infof("Destroying controller %q\nWaiting for resources to be reclaimed", controllerName)
@@ -145,7 +153,27 @@ func (c *killCommand) Run(ctx *cmd.Context) error {
 	if err := c.WaitForModels(ctx, api, uuid); err != nil {
 		c.DirectDestroyRemaining(ctx, api)
 	}
-	return c.environsDestroy(controllerName, controllerEnviron, cloudCallCtx, store)
+	return c.environsDestroy(controllerName, controllerEnviron, c.directDestroyContext(c.controllerCredentialAPIFunc), store)
+}
+
+// directDestroyContext returns a call context for destroying resources
+// directly through the cloud provider. If a direct destroy timeout was
+// given, the context's dying channel is closed once it has passed, so
+// that the provider stops waiting for resources to be deleted.
+func (c *killCommand) directDestroyContext(newAPIFunc newCredentialAPIFunc) context.ProviderCallContext {
+	callCtx := cloudCallContext(newAPIFunc)
+	if c.directDestroyTimeout > 0 {
+		dying := make(chan struct{})
+		timeout := c.clock.After(c.directDestroyTimeout)
+		go func() {
+			<-timeout
+			close(dying)
+		}()
+		callCtx.DyingFunc = func() <-chan struct{} {
+			return dying
+		}
+	}
+	return callCtx
 }
 
 func (c *killCommand) getControllerAPIWithTimeout(timeout time.Duration) (destroyControllerAPI, error) {
@@ -218,7 +246,7 @@ func (c *killCommand) DirectDestroyRemaining(ctx *cmd.Context, api destroyContro
 				hasErrors = true
 				continue
 			}
-			cloudCallCtx := cloudCallContext(c.credentialAPIFunctionForModel(model.Name))
+			cloudCallCtx := c.directDestroyContext(c.credentialAPIFunctionForModel(model.Name))
 			if err := env.Destroy(cloudCallCtx); err != nil {
 				logger.Errorf(err.Error())
 				hasErrors = true
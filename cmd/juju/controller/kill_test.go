@@ -24,6 +24,8 @@ import (
 	"github.com/juju/juju/cmd/cmdtest"
 	"github.com/juju/juju/cmd/juju/controller"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/context"
+	"github.com/juju/juju/jujuclient"
 	_ "github.com/juju/juju/provider/dummy"
 	coretesting "github.com/juju/juju/testing"
 )
@@ -411,6 +413,42 @@ func (s *KillSuite) TestKillDestroysControllerWithAPIError(c *gc.C) {
 	checkControllerRemovedFromStore(c, "test1", s.store)
 }
 
+func (s *KillSuite) newKillCommandWithDestroy(environsDestroy func(string, environs.ControllerDestroyer, context.ProviderCallContext, jujuclient.ControllerStore) error) cmd.Command {
+	return controller.NewKillCommandForTest(
+		s.api, s.clientapi, s.store, s.apierror, s.clock, nil,
+		func() (controller.CredentialAPI, error) { return s.controllerCredentialAPI, nil },
+		environsDestroy,
+	)
+}
+
+func (s *KillSuite) TestKillDirectDestroyTimeout(c *gc.C) {
+	s.api, s.apierror = nil, errors.New("connection refused")
+	cmd := s.newKillCommandWithDestroy(func(_ string, _ environs.ControllerDestroyer, ctx context.ProviderCallContext, _ jujuclient.ControllerStore) error {
+		dying := ctx.Dying()
+		c.Assert(dying, gc.NotNil)
+		s.clock.Advance(time.Minute)
+		select {
+		case <-dying:
+		case <-time.After(coretesting.LongWait):
+			c.Fatal("timed out waiting for the dying channel to be closed")
+		}
+		return errors.New(`deleting resource group "foo": canceled`)
+	})
+	_, err := cmdtesting.RunCommand(c, cmd, "test1", "-y", "--direct-destroy-timeout=1m")
+	c.Assert(err, gc.ErrorMatches, `deleting resource group "foo": canceled`)
+	checkControllerExistsInStore(c, "test1", s.store)
+}
+
+func (s *KillSuite) TestKillNoDirectDestroyTimeout(c *gc.C) {
+	s.api, s.apierror = nil, errors.New("connection refused")
+	cmd := s.newKillCommandWithDestroy(func(_ string, _ environs.ControllerDestroyer, ctx context.ProviderCallContext, _ jujuclient.ControllerStore) error {
+		c.Assert(ctx.Dying(), gc.IsNil)
+		return nil
+	})
+	_, err := cmdtesting.RunCommand(c, cmd, "test1", "-y")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (s *KillSuite) TestKillCommandConfirmation(c *gc.C) {
 	var stdin, stdout bytes.Buffer
 	ctx, err := cmd.DefaultContext()
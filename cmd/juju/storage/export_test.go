@@ -23,10 +23,15 @@ func NewPoolListCommandForTest(api PoolListAPI, store jujuclient.ClientStore) cm
 	return modelcmd.Wrap(cmd)
 }
 
-func NewPoolCreateCommandForTest(api PoolCreateAPI, store jujuclient.ClientStore) cmd.Command {
-	cmd := &poolCreateCommand{newAPIFunc: func() (PoolCreateAPI, error) {
-		return api, nil
-	}}
+func NewPoolCreateCommandForTest(api PoolCreateAPI, defaultAPI PoolDefaultAPI, store jujuclient.ClientStore) cmd.Command {
+	cmd := &poolCreateCommand{
+		newAPIFunc: func() (PoolCreateAPI, error) {
+			return api, nil
+		},
+		newDefaultAPIFunc: func() (PoolDefaultAPI, error) {
+			return defaultAPI, nil
+		},
+	}
 	cmd.SetClientStore(store)
 	return modelcmd.Wrap(cmd)
 }
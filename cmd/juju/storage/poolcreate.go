@@ -4,19 +4,44 @@
 package storage
 
 import (
+	"fmt"
+	"io/ioutil"
+	"sort"
 	"strings"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
+	"github.com/juju/gnuflag"
 	"github.com/juju/utils/keyvalues"
+	"gopkg.in/yaml.v2"
 
+	"github.com/juju/juju/api/modelconfig"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/storage"
 )
 
 // PoolCreateAPI defines the API methods that pool create command uses.
 type PoolCreateAPI interface {
 	Close() error
-	CreatePool(pname, ptype string, pconfig map[string]interface{}) error
+	CreatePool(pname, ptype string, pconfig map[string]interface{}) (params.StoragePool, error)
+	ValidatePool(pname, ptype string, pconfig map[string]interface{}) (params.StoragePool, error)
+}
+
+// PoolDefaultAPI defines the model config API methods that pool create
+// command uses to make a pool the default for a storage kind.
+type PoolDefaultAPI interface {
+	Close() error
+	ModelGetWithMetadata() (config.ConfigValues, error)
+	ModelSet(config map[string]interface{}) error
+}
+
+// defaultSourceKeys maps the kinds accepted by --default-for to the
+// model config attributes that hold the default storage source.
+var defaultSourceKeys = map[string]string{
+	storage.StorageKindBlock.String():      config.StorageDefaultBlockSourceKey,
+	storage.StorageKindFilesystem.String(): config.StorageDefaultFilesystemSourceKey,
 }
 
 const poolCreateCommandDoc = `
@@ -38,6 +63,43 @@ into named resources that can be used during deployment.
 Pools defined at the model level are easily reused across applications.
 Pool creation requires a pool name, the provider type and attributes for
 configuration as space-separated pairs, e.g. tags, size, path, etc.
+Attributes that are not specified take the provider's default values,
+where the provider defines them; the pool's effective attributes are
+displayed once it has been created.
+
+With --dry-run, the pool definition is validated by the storage provider
+and displayed with the provider's default values, but the pool is not
+created.
+
+With --default-for, the pool also becomes the model's default source for
+block or filesystem storage, by setting the storage-default-block-source
+or storage-default-filesystem-source model config. If the model already
+has a different default for that kind, the command fails without creating
+the pool unless --force is given; --dry-run performs the same check.
+
+With --manifest, the pools defined in a YAML file are created instead of a
+single pool given on the command line. The file uses the same format as the
+YAML output of list-storage-pools: a map of pool names to their provider and
+optional attrs. Each pool is validated and created in turn; a pool that
+cannot be created is reported without stopping the others.
+
+Examples:
+
+    juju create-storage-pool ebsrotary ebs volume-type=standard
+    juju create-storage-pool --dry-run ebsrotary ebs volume-type=standard
+    juju create-storage-pool --default-for block ebsssd ebs volume-type=ssd
+    juju create-storage-pool --manifest pools.yaml
+
+where pools.yaml contains, for example:
+
+    ebsrotary:
+      provider: ebs
+      attrs:
+        volume-type: standard
+    ebsssd:
+      provider: ebs
+      attrs:
+        volume-type: ssd
 `
 
 // NewPoolCreateCommand returns a command that creates or defines a storage pool
@@ -46,39 +108,69 @@ func NewPoolCreateCommand() cmd.Command {
 	cmd.newAPIFunc = func() (PoolCreateAPI, error) {
 		return cmd.NewStorageAPI()
 	}
+	cmd.newDefaultAPIFunc = func() (PoolDefaultAPI, error) {
+		root, err := cmd.NewAPIRoot()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return modelconfig.NewClient(root), nil
+	}
 	return modelcmd.Wrap(cmd)
 }
 
 // poolCreateCommand lists storage pools.
 type poolCreateCommand struct {
 	PoolCommandBase
-	newAPIFunc func() (PoolCreateAPI, error)
-	poolName   string
+	newAPIFunc        func() (PoolCreateAPI, error)
+	newDefaultAPIFunc func() (PoolDefaultAPI, error)
+	poolName          string
 	// TODO(anastasiamac 2015-01-29) type will need to become optional
 	// if type is unspecified, use the environment's default provider type
-	provider string
-	attrs    map[string]interface{}
+	provider   string
+	attrs      map[string]interface{}
+	dryRun     bool
+	defaultFor string
+	force      bool
+	manifest   string
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *poolCreateCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.PoolCommandBase.SetFlags(f)
+	f.BoolVar(&c.dryRun, "dry-run", false, "Validate the pool definition and display it, without creating it")
+	f.StringVar(&c.defaultFor, "default-for", "", "Make the pool the model's default for a storage kind (block or filesystem)")
+	f.BoolVar(&c.force, "force", false, "Replace an existing default when used with --default-for")
+	f.StringVar(&c.manifest, "manifest", "", "Create the pools defined in a YAML file")
 }
 
 // Init implements Command.Init.
 func (c *poolCreateCommand) Init(args []string) (err error) {
+	if c.manifest != "" {
+		if len(args) > 0 {
+			return errors.New("--manifest cannot be combined with a pool name, provider type or attributes")
+		}
+		if c.defaultFor != "" || c.force {
+			return errors.New("--manifest cannot be combined with --default-for or --force")
+		}
+		return nil
+	}
 	if len(args) < 2 {
 		return errors.New("pool creation requires names, provider type and optional attributes for configuration")
 	}
 
 	c.poolName = args[0]
 	c.provider = args[1]
+	if err := validatePoolDefinition(c.poolName, c.provider); err != nil {
+		return err
+	}
 
-	// poolName and provider can contain any character, except for '='.
-	// However, the last arguments are always expected to be key=value pairs.
-	// Since it's possible for users to mistype, we want to check here for cases
-	// such as:
-	//    $ juju create-storage-pool poolName key=value
-	//    $ juju create-storage-pool key=value poolName
-	// as either a provider or a pool name are missing.
-
-	if strings.Contains(c.poolName, "=") || strings.Contains(c.provider, "=") {
-		return errors.New("pool creation requires names and provider type before optional attributes for configuration")
+	if c.defaultFor != "" {
+		if _, ok := defaultSourceKeys[c.defaultFor]; !ok {
+			return errors.Errorf("--default-for must be %q or %q, got %q",
+				storage.StorageKindBlock, storage.StorageKindFilesystem, c.defaultFor)
+		}
+	} else if c.force {
+		return errors.New("--force requires --default-for")
 	}
 
 	options, err := keyvalues.Parse(args[2:], false)
@@ -96,11 +188,30 @@ func (c *poolCreateCommand) Init(args []string) (err error) {
 	return nil
 }
 
+// validatePoolDefinition checks the name and provider type of a pool
+// to be created.
+func validatePoolDefinition(poolName, provider string) error {
+	if poolName == "" || provider == "" {
+		return errors.New("pool creation requires names, provider type and optional attributes for configuration")
+	}
+	// poolName and provider can contain any character, except for '='.
+	// However, the last arguments are always expected to be key=value pairs.
+	// Since it's possible for users to mistype, we want to check here for cases
+	// such as:
+	//    $ juju create-storage-pool poolName key=value
+	//    $ juju create-storage-pool key=value poolName
+	// as either a provider or a pool name are missing.
+	if strings.Contains(poolName, "=") || strings.Contains(provider, "=") {
+		return errors.New("pool creation requires names and provider type before optional attributes for configuration")
+	}
+	return nil
+}
+
 // Info implements Command.Info.
 func (c *poolCreateCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "create-storage-pool",
-		Args:    "<name> <provider> [<key>=<value> [<key>=<value>...]]",
+		Args:    "(<name> <provider> [<key>=<value> [<key>=<value>...]] | --manifest <file>)",
 		Purpose: "Create or define a storage pool.",
 		Doc:     poolCreateCommandDoc,
 	}
@@ -108,10 +219,154 @@ func (c *poolCreateCommand) Info() *cmd.Info {
 
 // Run implements Command.Run.
 func (c *poolCreateCommand) Run(ctx *cmd.Context) (err error) {
+	if c.manifest != "" {
+		return c.createFromManifest(ctx)
+	}
+
+	// Check that the pool can become the default before anything
+	// is created, so that a rejected --default-for leaves no pool
+	// behind, and so that --dry-run reports the same failure.
+	var defaultAPI PoolDefaultAPI
+	if c.defaultFor != "" {
+		defaultAPI, err = c.newDefaultAPIFunc()
+		if err != nil {
+			return err
+		}
+		defer defaultAPI.Close()
+		if err := c.checkDefault(defaultAPI); err != nil {
+			return errors.Annotatef(err, "making storage pool %q the default for %s storage", c.poolName, c.defaultFor)
+		}
+	}
+
 	api, err := c.newAPIFunc()
 	if err != nil {
 		return err
 	}
 	defer api.Close()
-	return api.CreatePool(c.poolName, c.provider, c.attrs)
+	if c.dryRun {
+		pool, err := api.ValidatePool(c.poolName, c.provider, c.attrs)
+		if err != nil {
+			return err
+		}
+		ctx.Infof("Dry run: storage pool %q would be created.", c.poolName)
+		formatPoolsTabular(ctx.Stdout, map[string]PoolInfo{
+			pool.Name: {Provider: pool.Provider, Attrs: pool.Attrs},
+		})
+		return nil
+	}
+	pool, err := api.CreatePool(c.poolName, c.provider, c.attrs)
+	if err != nil {
+		return err
+	}
+	// Report the pool's effective attributes, which may
+	// include defaults supplied by the storage provider.
+	if len(pool.Attrs) > 0 {
+		ctx.Infof("Storage pool %q created with attributes: %s", pool.Name, formatPoolAttrs(pool.Attrs))
+	}
+
+	if defaultAPI != nil {
+		key := defaultSourceKeys[c.defaultFor]
+		if err := defaultAPI.ModelSet(map[string]interface{}{key: c.poolName}); err != nil {
+			return errors.Annotatef(err, "making storage pool %q the default for %s storage", c.poolName, c.defaultFor)
+		}
+		ctx.Infof("Storage pool %q is now the default for %s storage.", c.poolName, c.defaultFor)
+	}
+	return nil
+}
+
+// checkDefault checks that the pool may become the model's default
+// source for the storage kind given with --default-for. A default
+// already set on the model is only replaced if --force was given;
+// defaults inherited from the controller, region or provider are
+// always replaced.
+func (c *poolCreateCommand) checkDefault(api PoolDefaultAPI) error {
+	key := defaultSourceKeys[c.defaultFor]
+	attrs, err := api.ModelGetWithMetadata()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if current, ok := attrs[key]; ok && current.Source == config.JujuModelConfigSource && !c.force {
+		if value := fmt.Sprint(current.Value); value != "" && value != c.poolName {
+			return errors.Errorf("%s is already set to %q (use --force to replace it)", key, value)
+		}
+	}
+	return nil
+}
+
+// createFromManifest creates each of the pools defined in the manifest
+// file, in name order. Pools that are invalid or that cannot be created
+// are reported, and the remaining pools are still created.
+func (c *poolCreateCommand) createFromManifest(ctx *cmd.Context) error {
+	data, err := ioutil.ReadFile(ctx.AbsPath(c.manifest))
+	if err != nil {
+		return errors.Annotate(err, "reading storage pool manifest")
+	}
+	var pools map[string]PoolInfo
+	if err := yaml.Unmarshal(data, &pools); err != nil {
+		return errors.Annotatef(err, "parsing storage pool manifest %q", c.manifest)
+	}
+	if len(pools) == 0 {
+		return errors.Errorf("no storage pools defined in %q", c.manifest)
+	}
+	names := make([]string, 0, len(pools))
+	for name := range pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failed int
+	valid := make(map[string]PoolInfo)
+	for _, name := range names {
+		pool := pools[name]
+		if err := validatePoolDefinition(name, pool.Provider); err != nil {
+			ctx.Warningf("invalid storage pool %q: %v", name, err)
+			failed++
+			continue
+		}
+		if pool.Attrs == nil {
+			pool.Attrs = make(map[string]interface{})
+		}
+		valid[name] = pool
+	}
+
+	if len(valid) > 0 {
+		api, err := c.newAPIFunc()
+		if err != nil {
+			return err
+		}
+		defer api.Close()
+		for _, name := range names {
+			pool, ok := valid[name]
+			if !ok {
+				continue
+			}
+			if c.dryRun {
+				validated, err := api.ValidatePool(name, pool.Provider, pool.Attrs)
+				if err != nil {
+					ctx.Warningf("invalid storage pool %q: %v", name, err)
+					delete(valid, name)
+					failed++
+					continue
+				}
+				valid[name] = PoolInfo{Provider: validated.Provider, Attrs: validated.Attrs}
+				continue
+			}
+			if _, err := api.CreatePool(name, pool.Provider, pool.Attrs); err != nil {
+				ctx.Warningf("failed to create storage pool %q: %v", name, err)
+				failed++
+				continue
+			}
+			ctx.Infof("Created storage pool %q.", name)
+		}
+	}
+	if c.dryRun {
+		ctx.Infof("Dry run: %d storage pools would be created.", len(valid))
+		if len(valid) > 0 {
+			formatPoolsTabular(ctx.Stdout, valid)
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d storage pools could not be created", failed, len(names))
+	}
+	return nil
 }
@@ -4,18 +4,25 @@
 package storage_test
 
 import (
+	"io/ioutil"
+	"path/filepath"
+
 	"github.com/juju/cmd"
 	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/juju/storage"
+	"github.com/juju/juju/environs/config"
 	_ "github.com/juju/juju/provider/dummy"
 )
 
 type PoolCreateSuite struct {
 	SubStorageSuite
-	mockAPI *mockPoolCreateAPI
+	mockAPI        *mockPoolCreateAPI
+	mockDefaultAPI *mockPoolDefaultAPI
 }
 
 var _ = gc.Suite(&PoolCreateSuite{})
@@ -24,10 +31,11 @@ func (s *PoolCreateSuite) SetUpTest(c *gc.C) {
 	s.SubStorageSuite.SetUpTest(c)
 
 	s.mockAPI = &mockPoolCreateAPI{}
+	s.mockDefaultAPI = &mockPoolDefaultAPI{attrs: config.ConfigValues{}}
 }
 
 func (s *PoolCreateSuite) runPoolCreate(c *gc.C, args []string) (*cmd.Context, error) {
-	return cmdtesting.RunCommand(c, storage.NewPoolCreateCommandForTest(s.mockAPI, s.store), args...)
+	return cmdtesting.RunCommand(c, storage.NewPoolCreateCommandForTest(s.mockAPI, s.mockDefaultAPI, s.store), args...)
 }
 
 func (s *PoolCreateSuite) TestPoolCreateOneArg(c *gc.C) {
@@ -85,13 +93,304 @@ func (s *PoolCreateSuite) TestPoolCreateManyAttrs(c *gc.C) {
 	c.Check(err, jc.ErrorIsNil)
 }
 
+func (s *PoolCreateSuite) TestPoolCreateDryRun(c *gc.C) {
+	ctx, err := s.runPoolCreate(c, []string{"--dry-run", "sunshine", "lollypop", "something=too", "another=one"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `
+Name      Provider  Attrs
+sunshine  lollypop  another=one something=too
+`[1:])
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, "Dry run: storage pool \"sunshine\" would be created.\n")
+	c.Assert(s.mockAPI.validated, jc.DeepEquals, []string{"sunshine"})
+	c.Assert(s.mockAPI.created, gc.HasLen, 0)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateDryRunDisplaysEffectiveAttrs(c *gc.C) {
+	s.mockAPI.defaults = map[string]interface{}{"volume-type": "ssd", "something": "default"}
+	ctx, err := s.runPoolCreate(c, []string{"--dry-run", "sunshine", "lollypop", "something=too"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `
+Name      Provider  Attrs
+sunshine  lollypop  something=too volume-type=ssd
+`[1:])
+	c.Assert(s.mockAPI.created, gc.HasLen, 0)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateDryRunValidationError(c *gc.C) {
+	s.mockAPI.validateErrs = map[string]error{"sunshine": errors.New("validating storage provider config: no good")}
+	ctx, err := s.runPoolCreate(c, []string{"--dry-run", "sunshine", "lollypop", "something=too"})
+	c.Assert(err, gc.ErrorMatches, "validating storage provider config: no good")
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "")
+	c.Assert(s.mockAPI.created, gc.HasLen, 0)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateDryRunInvalidAttr(c *gc.C) {
+	_, err := s.runPoolCreate(c, []string{"--dry-run", "sunshine", "lollypop", "=too"})
+	c.Check(err, gc.ErrorMatches, `expected "key=value", got "=too"`)
+	c.Assert(s.mockAPI.created, gc.HasLen, 0)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateCallsAPI(c *gc.C) {
+	_, err := s.runPoolCreate(c, []string{"sunshine", "lollypop", "something=too"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mockAPI.created, jc.DeepEquals, []string{"sunshine"})
+}
+
+func (s *PoolCreateSuite) TestPoolCreateDisplaysEffectiveAttrs(c *gc.C) {
+	s.mockAPI.defaults = map[string]interface{}{"volume-type": "ssd", "something": "default"}
+	ctx, err := s.runPoolCreate(c, []string{"sunshine", "lollypop", "something=too"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, "")
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals,
+		"Storage pool \"sunshine\" created with attributes: something=too volume-type=ssd\n")
+}
+
+func (s *PoolCreateSuite) TestPoolCreateError(c *gc.C) {
+	s.mockAPI.createErrs = map[string]error{"sunshine": errors.New("boom")}
+	_, err := s.runPoolCreate(c, []string{"sunshine", "lollypop"})
+	c.Assert(err, gc.ErrorMatches, "boom")
+	c.Assert(s.mockAPI.created, gc.HasLen, 0)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateDefaultFor(c *gc.C) {
+	ctx, err := s.runPoolCreate(c, []string{"--default-for", "block", "sunshine", "lollypop"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mockAPI.created, jc.DeepEquals, []string{"sunshine"})
+	c.Assert(s.mockDefaultAPI.set, jc.DeepEquals, []map[string]interface{}{
+		{"storage-default-block-source": "sunshine"},
+	})
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals,
+		"Storage pool \"sunshine\" is now the default for block storage.\n")
+}
+
+func (s *PoolCreateSuite) TestPoolCreateDefaultForInvalidKind(c *gc.C) {
+	_, err := s.runPoolCreate(c, []string{"--default-for", "object", "sunshine", "lollypop"})
+	c.Assert(err, gc.ErrorMatches, `--default-for must be "block" or "filesystem", got "object"`)
+	c.Assert(s.mockAPI.created, gc.HasLen, 0)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateForceWithoutDefaultFor(c *gc.C) {
+	_, err := s.runPoolCreate(c, []string{"--force", "sunshine", "lollypop"})
+	c.Assert(err, gc.ErrorMatches, `--force requires --default-for`)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateDefaultForExisting(c *gc.C) {
+	s.mockDefaultAPI.attrs["storage-default-filesystem-source"] = config.ConfigValue{
+		Value:  "moonshine",
+		Source: "model",
+	}
+	_, err := s.runPoolCreate(c, []string{"--default-for", "filesystem", "sunshine", "lollypop"})
+	c.Assert(err, gc.ErrorMatches,
+		`making storage pool "sunshine" the default for filesystem storage: `+
+			`storage-default-filesystem-source is already set to "moonshine" \(use --force to replace it\)`)
+	c.Assert(s.mockAPI.created, gc.HasLen, 0)
+	c.Assert(s.mockDefaultAPI.set, gc.HasLen, 0)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateDefaultForExistingDryRun(c *gc.C) {
+	s.mockDefaultAPI.attrs["storage-default-filesystem-source"] = config.ConfigValue{
+		Value:  "moonshine",
+		Source: "model",
+	}
+	_, err := s.runPoolCreate(c, []string{"--dry-run", "--default-for", "filesystem", "sunshine", "lollypop"})
+	c.Assert(err, gc.ErrorMatches,
+		`making storage pool "sunshine" the default for filesystem storage: `+
+			`storage-default-filesystem-source is already set to "moonshine" \(use --force to replace it\)`)
+	c.Assert(s.mockAPI.created, gc.HasLen, 0)
+	c.Assert(s.mockDefaultAPI.set, gc.HasLen, 0)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateDefaultForDryRun(c *gc.C) {
+	_, err := s.runPoolCreate(c, []string{"--dry-run", "--default-for", "block", "sunshine", "lollypop"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mockAPI.created, gc.HasLen, 0)
+	c.Assert(s.mockDefaultAPI.set, gc.HasLen, 0)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateDefaultForExistingForce(c *gc.C) {
+	s.mockDefaultAPI.attrs["storage-default-filesystem-source"] = config.ConfigValue{
+		Value:  "moonshine",
+		Source: "model",
+	}
+	_, err := s.runPoolCreate(c, []string{"--default-for", "filesystem", "--force", "sunshine", "lollypop"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mockDefaultAPI.set, jc.DeepEquals, []map[string]interface{}{
+		{"storage-default-filesystem-source": "sunshine"},
+	})
+}
+
+func (s *PoolCreateSuite) TestPoolCreateDefaultForInherited(c *gc.C) {
+	s.mockDefaultAPI.attrs["storage-default-block-source"] = config.ConfigValue{
+		Value:  "ebs",
+		Source: "default",
+	}
+	_, err := s.runPoolCreate(c, []string{"--default-for", "block", "sunshine", "lollypop"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mockDefaultAPI.set, jc.DeepEquals, []map[string]interface{}{
+		{"storage-default-block-source": "sunshine"},
+	})
+}
+
+func (s *PoolCreateSuite) writeManifest(c *gc.C, content string) string {
+	path := filepath.Join(c.MkDir(), "pools.yaml")
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	return path
+}
+
+func (s *PoolCreateSuite) TestPoolCreateManifest(c *gc.C) {
+	path := s.writeManifest(c, `
+sunshine:
+  provider: lollypop
+  attrs:
+    something: too
+moonshine:
+  provider: lollypop
+`[1:])
+	ctx, err := s.runPoolCreate(c, []string{"--manifest", path})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mockAPI.created, jc.DeepEquals, []string{"moonshine", "sunshine"})
+	c.Assert(cmdtesting.Stderr(ctx), gc.Equals, `
+Created storage pool "moonshine".
+Created storage pool "sunshine".
+`[1:])
+}
+
+func (s *PoolCreateSuite) TestPoolCreateManifestPartialFailure(c *gc.C) {
+	s.mockAPI.createErrs = map[string]error{"moonshine": errors.New("boom")}
+	path := s.writeManifest(c, `
+sunshine:
+  provider: lollypop
+moonshine:
+  provider: lollypop
+starshine:
+  attrs:
+    something: too
+`[1:])
+	ctx, err := s.runPoolCreate(c, []string{"--manifest", path})
+	c.Assert(err, gc.ErrorMatches, "2 of 3 storage pools could not be created")
+	c.Assert(s.mockAPI.created, jc.DeepEquals, []string{"sunshine"})
+	stderr := cmdtesting.Stderr(ctx)
+	c.Assert(stderr, gc.Matches, `(?s).*invalid storage pool "starshine": pool creation requires names, provider type and optional attributes for configuration\n.*`)
+	c.Assert(stderr, gc.Matches, `(?s).*failed to create storage pool "moonshine": boom\n.*`)
+	c.Assert(stderr, gc.Matches, `(?s).*Created storage pool "sunshine".\n.*`)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateManifestPartialFailureExitStatus(c *gc.C) {
+	s.mockAPI.createErrs = map[string]error{"moonshine": errors.New("boom")}
+	path := s.writeManifest(c, `
+sunshine:
+  provider: lollypop
+moonshine:
+  provider: lollypop
+`[1:])
+	ctx := cmdtesting.Context(c)
+	code := cmd.Main(storage.NewPoolCreateCommandForTest(s.mockAPI, s.mockDefaultAPI, s.store), ctx, []string{"--manifest", path})
+	c.Assert(code, gc.Equals, 1)
+	c.Assert(s.mockAPI.created, jc.DeepEquals, []string{"sunshine"})
+}
+
+func (s *PoolCreateSuite) TestPoolCreateManifestDryRun(c *gc.C) {
+	path := s.writeManifest(c, `
+sunshine:
+  provider: lollypop
+  attrs:
+    something: too
+`[1:])
+	ctx, err := s.runPoolCreate(c, []string{"--dry-run", "--manifest", path})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `
+Name      Provider  Attrs
+sunshine  lollypop  something=too
+`[1:])
+	c.Assert(s.mockAPI.validated, jc.DeepEquals, []string{"sunshine"})
+	c.Assert(s.mockAPI.created, gc.HasLen, 0)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateManifestDryRunValidationError(c *gc.C) {
+	s.mockAPI.validateErrs = map[string]error{"moonshine": errors.New("no good")}
+	path := s.writeManifest(c, `
+sunshine:
+  provider: lollypop
+  attrs:
+    something: too
+moonshine:
+  provider: lollypop
+`[1:])
+	ctx, err := s.runPoolCreate(c, []string{"--dry-run", "--manifest", path})
+	c.Assert(err, gc.ErrorMatches, "1 of 2 storage pools could not be created")
+	c.Assert(cmdtesting.Stdout(ctx), gc.Equals, `
+Name      Provider  Attrs
+sunshine  lollypop  something=too
+`[1:])
+	c.Assert(cmdtesting.Stderr(ctx), gc.Matches, `(?s).*invalid storage pool "moonshine": no good\n.*`)
+	c.Assert(s.mockAPI.created, gc.HasLen, 0)
+}
+
+func (s *PoolCreateSuite) TestPoolCreateManifestWithArgs(c *gc.C) {
+	_, err := s.runPoolCreate(c, []string{"--manifest", "pools.yaml", "sunshine", "lollypop"})
+	c.Assert(err, gc.ErrorMatches, "--manifest cannot be combined with a pool name, provider type or attributes")
+}
+
+func (s *PoolCreateSuite) TestPoolCreateManifestEmpty(c *gc.C) {
+	path := s.writeManifest(c, "")
+	_, err := s.runPoolCreate(c, []string{"--manifest", path})
+	c.Assert(err, gc.ErrorMatches, `no storage pools defined in ".*pools.yaml"`)
+}
+
 type mockPoolCreateAPI struct {
+	created      []string
+	validated    []string
+	defaults     map[string]interface{}
+	createErrs   map[string]error
+	validateErrs map[string]error
+}
+
+func (s *mockPoolCreateAPI) CreatePool(pname, ptype string, pconfig map[string]interface{}) (params.StoragePool, error) {
+	if err := s.createErrs[pname]; err != nil {
+		return params.StoragePool{}, err
+	}
+	s.created = append(s.created, pname)
+	return s.pool(pname, ptype, pconfig), nil
+}
+
+func (s *mockPoolCreateAPI) ValidatePool(pname, ptype string, pconfig map[string]interface{}) (params.StoragePool, error) {
+	if err := s.validateErrs[pname]; err != nil {
+		return params.StoragePool{}, err
+	}
+	s.validated = append(s.validated, pname)
+	return s.pool(pname, ptype, pconfig), nil
+}
+
+func (s *mockPoolCreateAPI) pool(pname, ptype string, pconfig map[string]interface{}) params.StoragePool {
+	attrs := make(map[string]interface{})
+	for k, v := range s.defaults {
+		attrs[k] = v
+	}
+	for k, v := range pconfig {
+		attrs[k] = v
+	}
+	return params.StoragePool{Name: pname, Provider: ptype, Attrs: attrs}
+}
+
+func (s *mockPoolCreateAPI) Close() error {
+	return nil
+}
+
+type mockPoolDefaultAPI struct {
+	attrs config.ConfigValues
+	set   []map[string]interface{}
+}
+
+func (s *mockPoolDefaultAPI) ModelGetWithMetadata() (config.ConfigValues, error) {
+	return s.attrs, nil
 }
 
-func (s mockPoolCreateAPI) CreatePool(pname, ptype string, pconfig map[string]interface{}) error {
+func (s *mockPoolDefaultAPI) ModelSet(attrs map[string]interface{}) error {
+	s.set = append(s.set, attrs)
 	return nil
 }
 
-func (s mockPoolCreateAPI) Close() error {
+func (s *mockPoolDefaultAPI) Close() error {
 	return nil
 }
@@ -41,17 +41,23 @@ func formatPoolsTabular(writer io.Writer, pools map[string]PoolInfo) {
 	sort.Strings(poolNames)
 	for _, name := range poolNames {
 		pool := pools[name]
-		// order by key for deterministic return
-		keys := make([]string, 0, len(pool.Attrs))
-		for key := range pool.Attrs {
-			keys = append(keys, key)
-		}
-		sort.Strings(keys)
-		attrs := make([]string, len(pool.Attrs))
-		for i, key := range keys {
-			attrs[i] = fmt.Sprintf("%v=%v", key, pool.Attrs[key])
-		}
-		print(name, pool.Provider, strings.Join(attrs, " "))
+		print(name, pool.Provider, formatPoolAttrs(pool.Attrs))
 	}
 	tw.Flush()
 }
+
+// formatPoolAttrs returns the pool attributes as space-separated
+// key=value pairs, ordered by key.
+func formatPoolAttrs(poolAttrs map[string]interface{}) string {
+	// order by key for deterministic return
+	keys := make([]string, 0, len(poolAttrs))
+	for key := range poolAttrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := make([]string, len(poolAttrs))
+	for i, key := range keys {
+		attrs[i] = fmt.Sprintf("%v=%v", key, poolAttrs[key])
+	}
+	return strings.Join(attrs, " ")
+}
@@ -380,9 +380,10 @@ func runPoolCreate(c *gc.C, args ...string) (string, string, error) {
 
 func (s *cmdStorageSuite) TestCreatePool(c *gc.C) {
 	pname := "ftPool"
-	stdout, _, err := runPoolCreate(c, pname, "loop", "smth=one")
+	stdout, stderr, err := runPoolCreate(c, pname, "loop", "smth=one")
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(stdout, gc.Equals, "")
+	c.Assert(stderr, jc.Contains, `Storage pool "ftPool" created with attributes: smth=one`)
 	assertPoolExists(c, s.State, pname, "loop", "smth=one")
 }
 
@@ -413,6 +414,18 @@ func (s *cmdStorageSuite) TestCreatePoolErrorProviderType(c *gc.C) {
 	s.assertCreatePoolError(c, "", "not found", "loop", "ftPool", "smth=one")
 }
 
+func (s *cmdStorageSuite) TestCreatePoolDryRun(c *gc.C) {
+	stdout, _, err := runPoolCreate(c, "--dry-run", "ftPool", "loop", "smth=one")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stdout, jc.Contains, "ftPool  loop      smth=one")
+	_, err = poolmanager.New(state.NewStateSettings(s.State), nil).Get("ftPool")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *cmdStorageSuite) TestCreatePoolDryRunErrorProviderType(c *gc.C) {
+	s.assertCreatePoolError(c, "", "not found", "--dry-run", "ftPool", "notaprovider", "smth=one")
+}
+
 func (s *cmdStorageSuite) TestCreatePoolDuplicateName(c *gc.C) {
 	pname := "ftPool"
 	stdout, _, err := runPoolCreate(c, pname, "loop", "smth=one")
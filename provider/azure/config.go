@@ -4,18 +4,29 @@
 package azure
 
 import (
+	"crypto/tls"
+	"fmt"
 	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
 	"github.com/Azure/azure-sdk-for-go/arm/storage"
 	"github.com/juju/errors"
 	"github.com/juju/schema"
+	"github.com/juju/utils"
 	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/environs/config"
 )
 
 const (
-	configAttrStorageAccountType = "storage-account-type"
+	configAttrStorageAccountType   = "storage-account-type"
+	configAttrFaultDomainCount     = "fault-domain-count"
+	configAttrUpdateDomainCount    = "update-domain-count"
+	configAttrOSDiskCaching        = "os-disk-caching"
+	configAttrControllerStaticIP   = "controller-static-ip"
+	configAttrRetiredInstanceTypes = "retired-instance-types"
+	configAttrTLSMinVersion        = "tls-min-version"
+	configAttrTLSCipherSuites      = "tls-cipher-suites"
 
 	// The below bits are internal book-keeping things, rather than
 	// configuration. Config is just what we have to work with.
@@ -23,29 +34,121 @@ const (
 	// resourceNameLengthMax is the maximum length of resource
 	// names in Azure.
 	resourceNameLengthMax = 80
+
+	// maxFaultDomainCount and maxUpdateDomainCount are the largest
+	// fault and update domain counts that Azure accepts for an
+	// availability set. Not all regions support the maximum number
+	// of fault domains.
+	maxFaultDomainCount  = 3
+	maxUpdateDomainCount = 20
 )
 
 var configFields = schema.Fields{
-	configAttrStorageAccountType: schema.String(),
+	configAttrStorageAccountType:   schema.String(),
+	configAttrFaultDomainCount:     schema.ForceInt(),
+	configAttrUpdateDomainCount:    schema.ForceInt(),
+	configAttrOSDiskCaching:        schema.String(),
+	configAttrControllerStaticIP:   schema.Bool(),
+	configAttrRetiredInstanceTypes: schema.String(),
+	configAttrTLSMinVersion:        schema.String(),
+	configAttrTLSCipherSuites:      schema.String(),
 }
 
 var configDefaults = schema.Defaults{
-	configAttrStorageAccountType: string(storage.StandardLRS),
+	configAttrStorageAccountType:   string(storage.StandardLRS),
+	configAttrFaultDomainCount:     schema.Omit,
+	configAttrUpdateDomainCount:    schema.Omit,
+	configAttrOSDiskCaching:        string(compute.ReadWrite),
+	configAttrControllerStaticIP:   false,
+	configAttrRetiredInstanceTypes: "",
+	configAttrTLSMinVersion:        "1.2",
+	configAttrTLSCipherSuites:      "",
 }
 
+// immutableConfigAttributes are the attributes that may not be changed
+// once set. The availability set domain counts are fixed when the
+// availability set is created, and the TLS settings when the model's
+// Azure clients are created, so we do not allow them to change.
 var immutableConfigAttributes = []string{
 	configAttrStorageAccountType,
+	configAttrFaultDomainCount,
+	configAttrUpdateDomainCount,
+	configAttrTLSMinVersion,
+	configAttrTLSCipherSuites,
+}
+
+// createOnlyConfigAttributes are the immutable attributes that may
+// only be set when the model is created. Availability sets may already
+// exist by the time the config is updated, and Azure rejects attempts
+// to redeclare them with different domain counts.
+var createOnlyConfigAttributes = map[string]bool{
+	configAttrFaultDomainCount:  true,
+	configAttrUpdateDomainCount: true,
 }
 
 type azureModelConfig struct {
 	*config.Config
 	storageAccountType string
+
+	// faultDomainCount and updateDomainCount are the number of
+	// fault and update domains to create availability sets with.
+	// Zero means the count was not specified.
+	faultDomainCount  int
+	updateDomainCount int
+
+	// osDiskCaching is the host caching mode for the OS disks
+	// of new machines.
+	osDiskCaching compute.CachingTypes
+
+	// controllerStaticIP reports whether new controller machines
+	// are given a static, rather than dynamic, public IP address.
+	controllerStaticIP bool
+
+	// retiredInstanceTypes maps the VM sizes that may not be used
+	// to their replacements, which may be empty.
+	retiredInstanceTypes map[string]string
+
+	// tlsConfig is the TLS configuration used to talk to the Azure
+	// management and storage endpoints.
+	tlsConfig *tls.Config
 }
 
 var knownStorageAccountTypes = []string{
 	"Standard_LRS", "Standard_GRS", "Standard_RAGRS", "Standard_ZRS", "Premium_LRS",
 }
 
+var knownOSDiskCachingTypes = []string{
+	string(compute.None), string(compute.ReadOnly), string(compute.ReadWrite),
+}
+
+var knownTLSVersions = []string{"1.0", "1.1", "1.2"}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+}
+
+// tlsCipherSuites maps the names of the cipher suites that may be
+// specified in the tls-cipher-suites config to their IDs. Suites
+// using RC4 or 3DES are deliberately omitted.
+var tlsCipherSuites = map[string]uint16{
+	"TLS_RSA_WITH_AES_128_CBC_SHA":            tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_RSA_WITH_AES_256_CBC_SHA":            tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_RSA_WITH_AES_128_GCM_SHA256":         tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_RSA_WITH_AES_256_GCM_SHA384":         tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":   tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256": tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":   tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384": tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305":    tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305":  tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+}
+
 // Validate ensures that the provided configuration is valid for this
 // provider, and that changes between the old (if provided) and new
 // configurations are valid.
@@ -72,22 +175,30 @@ func validateConfig(newCfg, oldCfg *config.Config) (*azureModelConfig, error) {
 		// Ensure immutable configuration isn't changed.
 		oldUnknownAttrs := oldCfg.UnknownAttrs()
 		for _, key := range immutableConfigAttributes {
-			oldValue, hadValue := oldUnknownAttrs[key].(string)
-			if hadValue {
-				newValue, haveValue := validated[key].(string)
-				if !haveValue {
+			oldValue, hadValue := oldUnknownAttrs[key]
+			newValue, haveValue := validated[key]
+			if (!hadValue || oldValue == nil) && haveValue && newValue != nil && createOnlyConfigAttributes[key] {
+				return nil, errors.Errorf(
+					"cannot set immutable %q config after the model is created", key,
+				)
+			}
+			if hadValue && oldValue != nil {
+				if !haveValue || newValue == nil {
 					return nil, errors.Errorf(
 						"cannot remove immutable %q config", key,
 					)
 				}
-				if newValue != oldValue {
+				// Values may have been stored with a different
+				// numeric type than the schema coerces to, so
+				// compare their string forms.
+				if fmt.Sprint(newValue) != fmt.Sprint(oldValue) {
 					return nil, errors.Errorf(
 						"cannot change immutable %q config (%v -> %v)",
 						key, oldValue, newValue,
 					)
 				}
 			}
-			// It's valid to go from not having to having.
+			// Otherwise, it's valid to go from not having to having.
 		}
 	}
 
@@ -118,13 +229,133 @@ Please choose a model name of no more than %d characters.`,
 		)
 	}
 
+	var faultDomainCount, updateDomainCount int
+	if v, ok := validated[configAttrFaultDomainCount].(int); ok {
+		if v < 1 || v > maxFaultDomainCount {
+			return nil, errors.NotValidf(
+				"%s %d (expected 1-%d)",
+				configAttrFaultDomainCount, v, maxFaultDomainCount,
+			)
+		}
+		faultDomainCount = v
+	}
+	if v, ok := validated[configAttrUpdateDomainCount].(int); ok {
+		if v < 1 || v > maxUpdateDomainCount {
+			return nil, errors.NotValidf(
+				"%s %d (expected 1-%d)",
+				configAttrUpdateDomainCount, v, maxUpdateDomainCount,
+			)
+		}
+		updateDomainCount = v
+	}
+
+	osDiskCaching := validated[configAttrOSDiskCaching].(string)
+	if !isKnownOSDiskCachingType(osDiskCaching) {
+		return nil, errors.Errorf(
+			"invalid OS disk caching %q, expected one of: %q",
+			osDiskCaching, knownOSDiskCachingTypes,
+		)
+	}
+
+	retiredInstanceTypes, err := parseRetiredInstanceTypes(
+		validated[configAttrRetiredInstanceTypes].(string),
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	tlsConfig, err := newTLSConfig(
+		validated[configAttrTLSMinVersion].(string),
+		validated[configAttrTLSCipherSuites].(string),
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	azureConfig := &azureModelConfig{
-		newCfg,
-		storageAccountType,
+		Config:               newCfg,
+		storageAccountType:   storageAccountType,
+		faultDomainCount:     faultDomainCount,
+		updateDomainCount:    updateDomainCount,
+		osDiskCaching:        compute.CachingTypes(osDiskCaching),
+		controllerStaticIP:   validated[configAttrControllerStaticIP].(bool),
+		retiredInstanceTypes: retiredInstanceTypes,
+		tlsConfig:            tlsConfig,
 	}
 	return azureConfig, nil
 }
 
+// parseRetiredInstanceTypes parses the value of the retired-instance-types
+// config: a comma-separated list of VM sizes to treat as retired, each
+// optionally followed by "=replacement". The result maps the retired VM
+// sizes to their replacements, and includes the sizes that Azure has
+// retired unless overridden by the config.
+func parseRetiredInstanceTypes(value string) (map[string]string, error) {
+	result := make(map[string]string)
+	for name, replacement := range defaultRetiredInstanceTypes {
+		result[name] = replacement
+	}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		var name, replacement string
+		if i := strings.Index(entry, "="); i >= 0 {
+			name = strings.TrimSpace(entry[:i])
+			replacement = strings.TrimSpace(entry[i+1:])
+			if replacement == "" {
+				return nil, errors.NotValidf(
+					"%s entry %q (expected size[=replacement])",
+					configAttrRetiredInstanceTypes, entry,
+				)
+			}
+		} else {
+			name = entry
+		}
+		if name == "" {
+			return nil, errors.NotValidf(
+				"%s entry %q (expected size[=replacement])",
+				configAttrRetiredInstanceTypes, entry,
+			)
+		}
+		result[name] = replacement
+	}
+	return result, nil
+}
+
+// newTLSConfig returns the TLS configuration for talking to Azure,
+// given the values of the tls-min-version and tls-cipher-suites config.
+// The cipher suites are a comma-separated list of names; if there are
+// none, juju's standard set of cipher suites is used.
+func newTLSConfig(minVersion, cipherSuites string) (*tls.Config, error) {
+	version, ok := tlsVersions[minVersion]
+	if !ok {
+		return nil, errors.Errorf(
+			"invalid TLS version %q, expected one of: %q",
+			minVersion, knownTLSVersions,
+		)
+	}
+	tlsConfig := utils.SecureTLSConfig()
+	tlsConfig.MinVersion = version
+	if cipherSuites == "" {
+		return tlsConfig, nil
+	}
+	tlsConfig.CipherSuites = nil
+	for _, name := range strings.Split(cipherSuites, ",") {
+		name = strings.TrimSpace(name)
+		id, ok := tlsCipherSuites[name]
+		if !ok {
+			return nil, errors.NotValidf(
+				"%s entry %q (unknown or insecure cipher suite)",
+				configAttrTLSCipherSuites, name,
+			)
+		}
+		tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
+	}
+	return tlsConfig, nil
+}
+
 // isKnownStorageAccountType reports whether or not the given string identifies
 // a known storage account type.
 func isKnownStorageAccountType(t string) bool {
@@ -136,6 +367,17 @@ func isKnownStorageAccountType(t string) bool {
 	return false
 }
 
+// isKnownOSDiskCachingType reports whether or not the given string
+// identifies a known OS disk host caching mode.
+func isKnownOSDiskCachingType(t string) bool {
+	for _, knownType := range knownOSDiskCachingTypes {
+		if t == knownType {
+			return true
+		}
+	}
+	return false
+}
+
 // canonicalLocation returns the canonicalized location string. This involves
 // stripping whitespace, and lowercasing. The ARM APIs do not support embedded
 // whitespace, whereas the old Service Management APIs used to; we allow the
@@ -71,6 +71,125 @@ func (s *configSuite) TestValidateStorageAccountTypeCantChange(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `cannot change immutable "storage-account-type" config \(Standard_LRS -> Premium_LRS\)`)
 }
 
+func (s *configSuite) TestValidateDomainCounts(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{
+		"fault-domain-count":  3,
+		"update-domain-count": 20,
+	})
+}
+
+func (s *configSuite) TestValidateInvalidFaultDomainCount(c *gc.C) {
+	s.assertConfigInvalid(
+		c, testing.Attrs{"fault-domain-count": 4},
+		`fault-domain-count 4 \(expected 1-3\) not valid`,
+	)
+}
+
+func (s *configSuite) TestValidateInvalidUpdateDomainCount(c *gc.C) {
+	s.assertConfigInvalid(
+		c, testing.Attrs{"update-domain-count": 0},
+		`update-domain-count 0 \(expected 1-20\) not valid`,
+	)
+}
+
+func (s *configSuite) TestValidateFaultDomainCountCantChange(c *gc.C) {
+	cfgOld := makeTestModelConfig(c, testing.Attrs{"fault-domain-count": 2})
+	_, err := s.provider.Validate(cfgOld, cfgOld)
+	c.Assert(err, jc.ErrorIsNil)
+
+	cfgNew := makeTestModelConfig(c, testing.Attrs{"fault-domain-count": 3})
+	_, err = s.provider.Validate(cfgNew, cfgOld)
+	c.Assert(err, gc.ErrorMatches, `cannot change immutable "fault-domain-count" config \(2 -> 3\)`)
+
+	cfgNew = makeTestModelConfig(c)
+	_, err = s.provider.Validate(cfgNew, cfgOld)
+	c.Assert(err, gc.ErrorMatches, `cannot remove immutable "fault-domain-count" config`)
+}
+
+func (s *configSuite) TestValidateOSDiskCaching(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"os-disk-caching": "None"})
+}
+
+func (s *configSuite) TestValidateInvalidOSDiskCaching(c *gc.C) {
+	s.assertConfigInvalid(
+		c, testing.Attrs{"os-disk-caching": "WriteOnly"},
+		`invalid OS disk caching "WriteOnly", expected one of: \["None" "ReadOnly" "ReadWrite"\]`,
+	)
+}
+
+func (s *configSuite) TestValidateControllerStaticIP(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{"controller-static-ip": true})
+}
+
+func (s *configSuite) TestValidateInvalidControllerStaticIP(c *gc.C) {
+	s.assertConfigInvalid(
+		c, testing.Attrs{"controller-static-ip": "always"},
+		`.*expected bool, got string\("always"\)`,
+	)
+}
+
+func (s *configSuite) TestValidateRetiredInstanceTypes(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{
+		"retired-instance-types": "Standard_D1=Standard_D1_v2, D2",
+	})
+}
+
+func (s *configSuite) TestValidateInvalidRetiredInstanceTypes(c *gc.C) {
+	s.assertConfigInvalid(
+		c, testing.Attrs{"retired-instance-types": "Standard_D1,=Standard_D1_v2"},
+		`retired-instance-types entry "=Standard_D1_v2" \(expected size\[=replacement\]\) not valid`,
+	)
+	s.assertConfigInvalid(
+		c, testing.Attrs{"retired-instance-types": "Standard_D1="},
+		`retired-instance-types entry "Standard_D1=" \(expected size\[=replacement\]\) not valid`,
+	)
+}
+
+func (s *configSuite) TestValidateTLSSettings(c *gc.C) {
+	s.assertConfigValid(c, testing.Attrs{
+		"tls-min-version":   "1.1",
+		"tls-cipher-suites": "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	})
+}
+
+func (s *configSuite) TestValidateInvalidTLSMinVersion(c *gc.C) {
+	s.assertConfigInvalid(
+		c, testing.Attrs{"tls-min-version": "1.4"},
+		`invalid TLS version "1.4", expected one of: \["1.0" "1.1" "1.2"\]`,
+	)
+}
+
+func (s *configSuite) TestValidateInvalidTLSCipherSuites(c *gc.C) {
+	s.assertConfigInvalid(
+		c, testing.Attrs{"tls-cipher-suites": "TLS_RSA_WITH_RC4_128_SHA"},
+		`tls-cipher-suites entry "TLS_RSA_WITH_RC4_128_SHA" \(unknown or insecure cipher suite\) not valid`,
+	)
+}
+
+func (s *configSuite) TestValidateTLSMinVersionCantChange(c *gc.C) {
+	cfgOld := makeTestModelConfig(c, testing.Attrs{"tls-min-version": "1.2"})
+	_, err := s.provider.Validate(cfgOld, cfgOld)
+	c.Assert(err, jc.ErrorIsNil)
+
+	cfgNew := makeTestModelConfig(c, testing.Attrs{"tls-min-version": "1.1"})
+	_, err = s.provider.Validate(cfgNew, cfgOld)
+	c.Assert(err, gc.ErrorMatches, `cannot change immutable "tls-min-version" config \(1.2 -> 1.1\)`)
+}
+
+func (s *configSuite) TestValidateDomainCountsCantBeAdded(c *gc.C) {
+	cfgOld := makeTestModelConfig(c)
+	_, err := s.provider.Validate(cfgOld, cfgOld)
+	c.Assert(err, jc.ErrorIsNil)
+
+	cfgNew := makeTestModelConfig(c, testing.Attrs{"fault-domain-count": 2})
+	_, err = s.provider.Validate(cfgNew, cfgOld)
+	c.Assert(err, gc.ErrorMatches, `cannot set immutable "fault-domain-count" config after the model is created`)
+
+	cfgNew = makeTestModelConfig(c, testing.Attrs{"update-domain-count": 10})
+	_, err = s.provider.Validate(cfgNew, cfgOld)
+	c.Assert(err, gc.ErrorMatches, `cannot set immutable "update-domain-count" config after the model is created`)
+}
+
 func (s *configSuite) assertConfigValid(c *gc.C, attrs testing.Attrs) {
 	cfg := makeTestModelConfig(c, attrs)
 	_, err := s.provider.Validate(cfg, nil)
@@ -67,6 +67,11 @@ const (
 	// used for controller machines.
 	controllerAvailabilitySet = "juju-controller"
 
+	// maxCustomDataLength is the maximum length of the base64-encoded
+	// custom data Azure accepts for a VM. The decoded data may be at
+	// most 65535 bytes, which is 87380 bytes once encoded.
+	maxCustomDataLength = 87380
+
 	computeAPIVersion = "2016-04-30-preview"
 	networkAPIVersion = "2017-03-01"
 	storageAPIVersion = "2016-12-01"
@@ -109,6 +114,10 @@ type azureEnviron struct {
 	storageClient      azurestorage.Client
 	storageAccountName string
 
+	// httpClient is the HTTP client used by the Azure clients, if
+	// it was created for the model's TLS settings, and nil otherwise.
+	httpClient *http.Client
+
 	mu                     sync.Mutex
 	config                 *azureModelConfig
 	instanceTypes          map[string]instances.InstanceType
@@ -138,11 +147,13 @@ func newEnviron(
 		location:        canonicalLocation(cloud.Region),
 		storageEndpoint: storageEndpointURL.Host,
 	}
-	if err := env.initEnviron(); err != nil {
+	if err := env.SetConfig(cfg); err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	if err := env.SetConfig(cfg); err != nil {
+	// The model's TLS settings are needed to create the clients,
+	// so we must initialise them after validating the config.
+	if err := env.initEnviron(); err != nil {
 		return nil, errors.Trace(err)
 	}
 
@@ -168,9 +179,17 @@ func newEnviron(
 func (env *azureEnviron) initEnviron() error {
 	credAttrs := env.cloud.Credential.Attributes()
 	env.subscriptionId = credAttrs[credAttrSubscriptionId]
+
+	// All of the model's clients, including the blob storage client,
+	// talk to Azure using the model's TLS settings.
+	sender := env.provider.config.Sender
+	if newSender := env.provider.config.NewSender; newSender != nil {
+		env.httpClient = newSender(env.config.tlsConfig)
+		sender = env.httpClient
+	}
 	env.authorizer = &cloudSpecAuth{
 		cloud:  env.cloud,
-		sender: env.provider.config.Sender,
+		sender: sender,
 	}
 
 	env.compute = compute.NewWithBaseURI(env.cloud.Endpoint, env.subscriptionId)
@@ -189,8 +208,8 @@ func (env *azureEnviron) initEnviron() error {
 		useragent.UpdateClient(client)
 		client.Authorizer = env.authorizer
 		logger := loggo.GetLogger(id)
-		if env.provider.config.Sender != nil {
-			client.Sender = env.provider.config.Sender
+		if sender != nil {
+			client.Sender = sender
 		}
 		client.ResponseInspector = tracing.RespondDecorator(logger)
 		client.RequestInspector = tracing.PrepareDecorator(logger)
@@ -231,6 +250,11 @@ func (env *azureEnviron) Bootstrap(
 	callCtx context.ProviderCallContext,
 	args environs.BootstrapParams,
 ) (*environs.BootstrapResult, error) {
+	// Check that the controller can be provisioned before we
+	// create anything, so we don't leave resources behind.
+	if err := env.checkBootstrapConstraints(callCtx, args.BootstrapConstraints); err != nil {
+		return nil, errors.Trace(err)
+	}
 	if err := env.initResourceGroup(callCtx, args.ControllerConfig.ControllerUUID(), true); err != nil {
 		return nil, errors.Annotate(err, "creating controller resource group")
 	}
@@ -245,6 +269,26 @@ func (env *azureEnviron) Bootstrap(
 	return result, nil
 }
 
+// checkBootstrapConstraints returns an error if no instance type in the
+// environment's location satisfies the controller's constraints.
+func (env *azureEnviron) checkBootstrapConstraints(ctx context.ProviderCallContext, cons constraints.Value) error {
+	instanceTypes, err := env.getInstanceTypes(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	instanceTypes = withoutRetiredInstanceTypes(instanceTypes, env.retiredInstanceTypes())
+	allInstanceTypes := make([]instances.InstanceType, 0, len(instanceTypes))
+	for _, instanceType := range instanceTypes {
+		allInstanceTypes = append(allInstanceTypes, instanceType)
+	}
+	if _, err := instances.MatchingInstanceTypes(
+		allInstanceTypes, env.location, defaultToBaselineSpec(cons),
+	); err != nil {
+		return errors.Annotate(err, "checking controller constraints")
+	}
+	return nil
+}
+
 // initResourceGroup creates a resource group for this environment.
 func (env *azureEnviron) initResourceGroup(ctx context.ProviderCallContext, controllerUUID string, controller bool) error {
 	resourceGroupsClient := resources.GroupsClient{env.resources}
@@ -321,7 +365,7 @@ func (env *azureEnviron) createCommonResourceDeployment(
 func (env *azureEnviron) ControllerInstances(ctx context.ProviderCallContext, controllerUUID string) ([]instance.Id, error) {
 	instances, err := env.allInstances(ctx, env.resourceGroup, false, true)
 	if err != nil {
-		return nil, err
+		return nil, errors.Trace(err)
 	}
 	if len(instances) == 0 {
 		return nil, environs.ErrNoInstances
@@ -351,7 +395,7 @@ func (env *azureEnviron) SetConfig(cfg *config.Config) error {
 	}
 	ecfg, err := validateConfig(cfg, old)
 	if err != nil {
-		return err
+		return errors.Trace(err)
 	}
 	env.config = ecfg
 
@@ -362,7 +406,7 @@ func (env *azureEnviron) SetConfig(cfg *config.Config) error {
 func (env *azureEnviron) ConstraintsValidator(ctx context.ProviderCallContext) (constraints.Validator, error) {
 	instanceTypes, err := env.getInstanceTypes(ctx)
 	if err != nil {
-		return nil, err
+		return nil, errors.Trace(err)
 	}
 	instTypeNames := make([]string, 0, len(instanceTypes))
 	for instTypeName := range instanceTypes {
@@ -392,28 +436,58 @@ func (env *azureEnviron) ConstraintsValidator(ctx context.ProviderCallContext) (
 			constraints.Arch,
 		},
 	)
-	return validator, nil
+	return &retiredInstanceTypeValidator{
+		Validator: validator,
+		retired:   env.retiredInstanceTypes(),
+	}, nil
+}
+
+// retiredInstanceTypeValidator is a constraints.Validator that rejects
+// retired instance types, suggesting their replacements. Retired types
+// are otherwise valid, so that they are not reported as unknown.
+type retiredInstanceTypeValidator struct {
+	constraints.Validator
+	retired map[string]string
+}
+
+// Validate is part of the constraints.Validator interface.
+func (v *retiredInstanceTypeValidator) Validate(cons constraints.Value) ([]string, error) {
+	if cons.HasInstanceType() {
+		if replacement, ok := retiredInstanceType(v.retired, *cons.InstanceType); ok {
+			return nil, retiredInstanceTypeError(*cons.InstanceType, replacement)
+		}
+	}
+	return v.Validator.Validate(cons)
 }
 
 // PrecheckInstance is defined on the environs.InstancePrechecker interface.
 func (env *azureEnviron) PrecheckInstance(ctx context.ProviderCallContext, args environs.PrecheckInstanceParams) error {
 	if args.Placement != "" {
-		return fmt.Errorf("unknown placement directive: %s", args.Placement)
+		return errors.NewNotValid(nil, fmt.Sprintf(
+			"unknown placement directive: %s", args.Placement,
+		))
 	}
 	if !args.Constraints.HasInstanceType() {
 		return nil
 	}
 	// Constraint has an instance-type constraint so let's see if it is valid.
+	if replacement, ok := retiredInstanceType(
+		env.retiredInstanceTypes(), *args.Constraints.InstanceType,
+	); ok {
+		return retiredInstanceTypeError(*args.Constraints.InstanceType, replacement)
+	}
 	instanceTypes, err := env.getInstanceTypes(ctx)
 	if err != nil {
-		return err
+		return errors.Trace(err)
 	}
 	for _, instanceType := range instanceTypes {
 		if instanceType.Name == *args.Constraints.InstanceType {
 			return nil
 		}
 	}
-	return fmt.Errorf("invalid instance type %q", *args.Constraints.InstanceType)
+	return errors.NewNotValid(nil, fmt.Sprintf(
+		"invalid instance type %q", *args.Constraints.InstanceType,
+	))
 }
 
 // MaintainInstance is specified in the InstanceBroker interface.
@@ -437,7 +511,12 @@ func (env *azureEnviron) StartInstance(ctx context.ProviderCallContext, args env
 		env.config,
 	)
 	storageAccountType := env.config.storageAccountType
+	faultDomainCount := env.config.faultDomainCount
+	updateDomainCount := env.config.updateDomainCount
+	osDiskCaching := env.config.osDiskCaching
+	controllerStaticIP := env.config.controllerStaticIP
 	imageStream := env.config.ImageStream()
+	retiredInstanceTypes := env.config.retiredInstanceTypes
 	instanceTypes, err := env.getInstanceTypesLocked(ctx)
 	if err != nil {
 		env.mu.Unlock()
@@ -445,6 +524,17 @@ func (env *azureEnviron) StartInstance(ctx context.ProviderCallContext, args env
 	}
 	env.mu.Unlock()
 
+	// Retired instance types may not be asked for, and are never
+	// chosen to satisfy other constraints.
+	if args.Constraints.HasInstanceType() {
+		if replacement, ok := retiredInstanceType(
+			retiredInstanceTypes, *args.Constraints.InstanceType,
+		); ok {
+			return nil, retiredInstanceTypeError(*args.Constraints.InstanceType, replacement)
+		}
+	}
+	instanceTypes = withoutRetiredInstanceTypes(instanceTypes, retiredInstanceTypes)
+
 	// If the user has not specified a root-disk size, then
 	// set a sensible default.
 	var rootDisk uint64
@@ -472,7 +562,7 @@ func (env *azureEnviron) StartInstance(ctx context.ProviderCallContext, args env
 		imageStream,
 	)
 	if err != nil {
-		return nil, err
+		return nil, errors.Trace(err)
 	}
 	if rootDisk < instanceSpec.InstanceType.RootDisk {
 		// The InstanceType's RootDisk is set to the maximum
@@ -510,7 +600,7 @@ func (env *azureEnviron) StartInstance(ctx context.ProviderCallContext, args env
 	if err := instancecfg.FinishInstanceConfig(
 		args.InstanceConfig, env.Config(),
 	); err != nil {
-		return nil, err
+		return nil, errors.Trace(err)
 	}
 
 	machineTag := names.NewMachineTag(args.InstanceConfig.MachineId)
@@ -527,7 +617,9 @@ func (env *azureEnviron) StartInstance(ctx context.ProviderCallContext, args env
 	if err := env.createVirtualMachine(
 		ctx, vmName, vmTags, envTags,
 		instanceSpec, args.InstanceConfig,
-		storageAccountType,
+		storageAccountType, osDiskCaching,
+		faultDomainCount, updateDomainCount,
+		controllerStaticIP,
 	); err != nil {
 		logger.Errorf("creating instance failed, destroying: %v", err)
 		if err := env.StopInstances(ctx, instance.Id(vmName)); err != nil {
@@ -564,6 +656,9 @@ func (env *azureEnviron) createVirtualMachine(
 	instanceSpec *instances.InstanceSpec,
 	instanceConfig *instancecfg.InstanceConfig,
 	storageAccountType string,
+	osDiskCaching compute.CachingTypes,
+	faultDomainCount, updateDomainCount int,
+	controllerStaticIP bool,
 ) error {
 	deploymentsClient := resources.DeploymentsClient{
 		ManagementClient: env.resources,
@@ -598,11 +693,16 @@ func (env *azureEnviron) createVirtualMachine(
 		))
 	} else {
 		// Wait for the common resource deployment to complete.
+		waitStart := time.Now()
 		if err := env.waitCommonResourcesCreated(); err != nil {
 			return errors.Annotate(
 				err, "waiting for common resources to be created",
 			)
 		}
+		logger.Debugf(
+			"- waited %v for common resources (%s)",
+			time.Since(waitStart), vmName,
+		)
 	}
 
 	maybeStorageAccount, err := env.getStorageAccount()
@@ -628,6 +728,7 @@ func (env *azureEnviron) createVirtualMachine(
 		vmName,
 		maybeStorageAccount,
 		storageAccountType,
+		osDiskCaching,
 		instanceSpec,
 	)
 	if err != nil {
@@ -647,23 +748,46 @@ func (env *azureEnviron) createVirtualMachine(
 			availabilitySetName,
 		)
 		var availabilitySetProperties interface{}
+		properties := &compute.AvailabilitySetProperties{}
 		if maybeStorageAccount == nil {
 			// This model uses managed disks; we must create
 			// the availability set as "aligned" to support
 			// them.
-			availabilitySetProperties = &compute.AvailabilitySetProperties{
-				// Managed means the availability set is
-				// "aligned", allowing managed disks to be
-				// used.
-				Managed: to.BoolPtr(true),
-
-				// Azure complains when the fault domain count
-				// is not specified, even though it is meant
-				// to be optional and default to the maximum.
-				// The maximum depends on the location, and
-				// there is no API to query it.
-				PlatformFaultDomainCount: to.Int32Ptr(maxFaultDomains(env.location)),
+			//
+			// Managed means the availability set is
+			// "aligned", allowing managed disks to be
+			// used.
+			properties.Managed = to.BoolPtr(true)
+
+			// Not all regions support the maximum number of
+			// fault domains for aligned availability sets;
+			// check the count now, rather than failing at
+			// deployment time.
+			if max := maxFaultDomains(env.location); int32(faultDomainCount) > max {
+				return errors.NotValidf(
+					"%s %d in region %q (expected 1-%d)",
+					configAttrFaultDomainCount, faultDomainCount, env.location, max,
+				)
 			}
+
+			// Azure complains when the fault domain count
+			// is not specified, even though it is meant
+			// to be optional and default to the maximum.
+			// The maximum depends on the location, and
+			// there is no API to query it.
+			properties.PlatformFaultDomainCount = to.Int32Ptr(maxFaultDomains(env.location))
+			availabilitySetProperties = properties
+		}
+		// Fault and update domain counts specified in model
+		// config override the Azure defaults. They can only
+		// be set when the availability set is first created.
+		if faultDomainCount > 0 {
+			properties.PlatformFaultDomainCount = to.Int32Ptr(int32(faultDomainCount))
+			availabilitySetProperties = properties
+		}
+		if updateDomainCount > 0 {
+			properties.PlatformUpdateDomainCount = to.Int32Ptr(int32(updateDomainCount))
+			availabilitySetProperties = properties
 		}
 		resources = append(resources, armtemplates.Resource{
 			APIVersion: computeAPIVersion,
@@ -679,6 +803,15 @@ func (env *azureEnviron) createVirtualMachine(
 		vmDependsOn = append(vmDependsOn, availabilitySetId)
 	}
 
+	// If configured, controllers are allocated a static public IP
+	// address, so that the API endpoint that clients hold on to does
+	// not change when the controller VM is stopped or deallocated.
+	// The address is released along with the rest of the VM's
+	// resources when the machine is stopped or the model destroyed.
+	publicIPAllocationMethod := network.Dynamic
+	if instanceConfig.Controller != nil && controllerStaticIP {
+		publicIPAllocationMethod = network.Static
+	}
 	publicIPAddressName := vmName + "-public-ip"
 	publicIPAddressId := fmt.Sprintf(`[resourceId('Microsoft.Network/publicIPAddresses', '%s')]`, publicIPAddressName)
 	resources = append(resources, armtemplates.Resource{
@@ -688,7 +821,7 @@ func (env *azureEnviron) createVirtualMachine(
 		Location:   env.location,
 		Tags:       vmTags,
 		Properties: &network.PublicIPAddressPropertiesFormat{
-			PublicIPAllocationMethod: network.Dynamic,
+			PublicIPAllocationMethod: publicIPAllocationMethod,
 		},
 	})
 
@@ -796,6 +929,7 @@ func (env *azureEnviron) createVirtualMachine(
 	deploymentsClient.ResponseInspector = asyncCreationRespondDecorator(
 		deploymentsClient.ResponseInspector,
 	)
+	deploymentStart := time.Now()
 	if err := createDeployment(
 		ctx,
 		deploymentsClient,
@@ -803,8 +937,16 @@ func (env *azureEnviron) createVirtualMachine(
 		vmName, // deployment name
 		template,
 	); err != nil {
+		logger.Debugf(
+			"- virtual machine deployment failed after %v (%s)",
+			time.Since(deploymentStart), vmName,
+		)
 		return errors.Trace(err)
 	}
+	logger.Debugf(
+		"- created virtual machine deployment in %v (%s)",
+		time.Since(deploymentStart), vmName,
+	)
 	return nil
 }
 
@@ -880,6 +1022,12 @@ type deploymentIncompleteError struct {
 	error
 }
 
+// transientError wraps an error returned by a failed Azure API call that
+// may succeed if retried.
+type transientError struct {
+	error
+}
+
 func (env *azureEnviron) waitCommonResourcesCreatedLocked() (*resources.DeploymentExtended, error) {
 	deploymentsClient := resources.DeploymentsClient{env.resources}
 
@@ -980,10 +1128,17 @@ func availabilitySetName(
 
 // newStorageProfile creates the storage profile for a virtual machine,
 // based on the series and chosen instance spec.
+//
+// The OS disk is created with the given host caching mode. ReadWrite,
+// the default, gives the best general performance; ReadOnly avoids
+// holding writes in the host cache, which is recommended for databases
+// that need writes to be durable; None bypasses the cache altogether,
+// which suits heavy sequential or write-only workloads.
 func newStorageProfile(
 	vmName string,
 	maybeStorageAccount *storage.Account,
 	storageAccountType string,
+	osDiskCaching compute.CachingTypes,
 	instanceSpec *instances.InstanceSpec,
 ) (*compute.StorageProfile, error) {
 	logger.Debugf("creating storage profile for %q", vmName)
@@ -1002,7 +1157,7 @@ func newStorageProfile(
 	osDisk := &compute.OSDisk{
 		Name:         to.StringPtr(osDiskName),
 		CreateOption: compute.FromImage,
-		Caching:      compute.ReadWrite,
+		Caching:      osDiskCaching,
 		DiskSizeGB:   to.Int32Ptr(int32(osDiskSizeGB)),
 	}
 
@@ -1046,6 +1201,16 @@ func newOSProfile(
 	if err != nil {
 		return nil, os.Unknown, errors.Annotate(err, "composing user data")
 	}
+	if n := len(customData); n > maxCustomDataLength {
+		// Azure rejects oversized custom data deep inside the
+		// deployment, with an error that doesn't mention why.
+		// Fail early with something the user can act on.
+		return nil, os.Unknown, errors.Errorf(
+			"custom data exceeds Azure limit of %d bytes (got %d); "+
+				"reduce the size of the model's cloudinit-userdata",
+			maxCustomDataLength, n,
+		)
+	}
 
 	osProfile := &compute.OSProfile{
 		ComputerName: to.StringPtr(vmName),
@@ -1129,7 +1294,7 @@ func (env *azureEnviron) StopInstances(ctx context.ProviderCallContext, ids ...i
 		if err == nil {
 			existing++
 		} else if !errors.IsNotFound(err) {
-			return err
+			return errors.Trace(err)
 		}
 	}
 	if existing == 0 {
@@ -1495,8 +1660,12 @@ func (env *azureEnviron) allInstances(
 	controllerOnly bool,
 ) ([]instance.Instance, error) {
 	deploymentsClient := resources.DeploymentsClient{env.resources}
-	deploymentsResult, err := deploymentsClient.List(resourceGroup, "", nil)
-	if err != nil {
+	var deploymentsResult resources.DeploymentListResult
+	if err := env.retryTransient("listing deployments", func() error {
+		var err error
+		deploymentsResult, err = deploymentsClient.List(resourceGroup, "", nil)
+		return transientResponseError(deploymentsResult.Response, err)
+	}); err != nil {
 		if isNotFoundResponse(deploymentsResult.Response) {
 			// This will occur if the resource group does not
 			// exist, e.g. in a fresh hosted environment.
@@ -1504,29 +1673,43 @@ func (env *azureEnviron) allInstances(
 		}
 		return nil, errorutils.HandleCredentialError(errors.Trace(err), ctx)
 	}
-	if deploymentsResult.Value == nil || len(*deploymentsResult.Value) == 0 {
-		return nil, nil
-	}
 
-	azureInstances := make([]*azureInstance, 0, len(*deploymentsResult.Value))
-	for _, deployment := range *deploymentsResult.Value {
-		name := to.String(deployment.Name)
-		if _, err := names.ParseMachineTag(name); err != nil {
-			// Deployments we create for Juju machines are named
-			// with the machine tag. We also create a "common"
-			// deployment, so this will exclude that VM and any
-			// other stray deployment resources.
-			continue
-		}
-		if deployment.Properties == nil || deployment.Properties.Dependencies == nil {
-			continue
+	var azureInstances []*azureInstance
+	for deploymentsResult.Value != nil {
+		for _, deployment := range *deploymentsResult.Value {
+			name := to.String(deployment.Name)
+			if _, err := names.ParseMachineTag(name); err != nil {
+				// Deployments we create for Juju machines are named
+				// with the machine tag. We also create a "common"
+				// deployment, so this will exclude that VM and any
+				// other stray deployment resources.
+				continue
+			}
+			if deployment.Properties == nil || deployment.Properties.Dependencies == nil {
+				continue
+			}
+			if controllerOnly && !isControllerDeployment(deployment) {
+				continue
+			}
+			provisioningState := to.String(deployment.Properties.ProvisioningState)
+			inst := &azureInstance{name, provisioningState, env, nil, nil}
+			azureInstances = append(azureInstances, inst)
 		}
-		if controllerOnly && !isControllerDeployment(deployment) {
-			continue
+		// Large resource groups may have more
+		// deployments than fit in one page.
+		lastResult := deploymentsResult
+		if err := env.retryTransient("listing deployments", func() error {
+			var err error
+			deploymentsResult, err = deploymentsClient.ListNextResults(lastResult)
+			return transientResponseError(deploymentsResult.Response, err)
+		}); err != nil {
+			return nil, errorutils.HandleCredentialError(
+				errors.Annotate(err, "listing deployments"), ctx,
+			)
 		}
-		provisioningState := to.String(deployment.Properties.ProvisioningState)
-		inst := &azureInstance{name, provisioningState, env, nil, nil}
-		azureInstances = append(azureInstances, inst)
+	}
+	if len(azureInstances) == 0 {
+		return nil, nil
 	}
 
 	if len(azureInstances) > 0 && refreshAddresses {
@@ -1571,8 +1754,7 @@ func isControllerDeployment(deployment resources.DeploymentExtended) bool {
 // Destroy is specified in the Environ interface.
 func (env *azureEnviron) Destroy(ctx context.ProviderCallContext) error {
 	logger.Debugf("destroying model %q", env.envName)
-	logger.Debugf("- deleting resource group %q", env.resourceGroup)
-	if err := env.deleteResourceGroup(ctx, env.resourceGroup); err != nil {
+	if err := env.deleteResourceGroups(ctx, []string{env.resourceGroup}); err != nil {
 		return errors.Trace(err)
 	}
 	// Resource groups are self-contained and fully encompass
@@ -1600,57 +1782,108 @@ func (env *azureEnviron) deleteControllerManagedResourceGroups(ctx context.Provi
 		tags.JujuController, controllerUUID,
 	)
 	client := resources.GroupsClient{env.resources}
-	result, err := client.List(filter, nil)
-	if err != nil {
+	var result resources.GroupListResult
+	if err := env.retryTransient("listing resource groups", func() error {
+		var err error
+		result, err = client.List(filter, nil)
+		return transientResponseError(result.Response, err)
+	}); err != nil {
 		return errorutils.HandleCredentialError(errors.Annotate(err, "listing resource groups"), ctx)
 	}
-	if result.Value == nil {
-		return nil
+	var groups []string
+	for result.Value != nil {
+		for _, group := range *result.Value {
+			groups = append(groups, to.String(group.Name))
+		}
+		lastResult := result
+		if err := env.retryTransient("listing resource groups", func() error {
+			var err error
+			result, err = client.ListNextResults(lastResult)
+			return transientResponseError(result.Response, err)
+		}); err != nil {
+			return errorutils.HandleCredentialError(errors.Annotate(err, "listing resource groups"), ctx)
+		}
 	}
+	return env.deleteResourceGroups(ctx, groups)
+}
+
+// DestroyError is returned by Destroy and DestroyController when some
+// of the resource groups holding the model's or controller's resources
+// could not be deleted, for example because the call context's dying
+// channel was closed before their deletion completed. Destroying again
+// deletes the groups that remain.
+type DestroyError struct {
+	// Deleted holds the names of the resource groups that were deleted.
+	Deleted []string
+
+	// Incomplete maps the names of the resource groups that were not
+	// deleted to the reason they were not.
+	Incomplete map[string]error
+}
 
+// Error is part of the error interface.
+func (e *DestroyError) Error() string {
+	groups := make([]string, 0, len(e.Incomplete))
+	for group := range e.Incomplete {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	messages := make([]string, len(groups))
+	for i, group := range groups {
+		messages[i] = e.Incomplete[group].Error()
+	}
+	message := strings.Join(messages, "; ")
+	if len(e.Deleted) > 0 {
+		message += fmt.Sprintf(" (deleted resource groups: %s)", strings.Join(e.Deleted, ", "))
+	}
+	return message
+}
+
+// deleteResourceGroups deletes the named resource groups, logging each
+// one as its deletion completes. If any of the groups is not deleted,
+// a *DestroyError is returned.
+func (env *azureEnviron) deleteResourceGroups(ctx context.ProviderCallContext, groups []string) error {
 	// Deleting groups can take a long time, so make sure they are
 	// deleted in parallel.
 	var wg sync.WaitGroup
-	errs := make([]error, len(*result.Value))
-	for i, group := range *result.Value {
-		groupName := to.String(group.Name)
-		logger.Debugf("  - deleting resource group %q", groupName)
+	errs := make([]error, len(groups))
+	for i, group := range groups {
+		logger.Debugf("- deleting resource group %q", group)
 		wg.Add(1)
-		go func(i int) {
+		go func(i int, group string) {
 			defer wg.Done()
-			if err := env.deleteResourceGroup(ctx, groupName); err != nil {
-				errs[i] = errors.Annotatef(
-					err, "deleting resource group %q", groupName,
-				)
+			if err := env.deleteResourceGroup(ctx, group); err != nil {
+				errs[i] = err
+				return
 			}
-		}(i)
+			logger.Infof("deleted resource group %q", group)
+		}(i, group)
 	}
 	wg.Wait()
 
-	// If there is just one error, return it. If there are multiple,
-	// then combine their messages.
-	var nonNilErrs []error
-	for _, err := range errs {
+	destroyErr := &DestroyError{Incomplete: make(map[string]error)}
+	for i, err := range errs {
 		if err != nil {
-			nonNilErrs = append(nonNilErrs, err)
+			destroyErr.Incomplete[groups[i]] = err
+		} else {
+			destroyErr.Deleted = append(destroyErr.Deleted, groups[i])
 		}
 	}
-	switch len(nonNilErrs) {
-	case 0:
+	if len(destroyErr.Incomplete) == 0 {
 		return nil
-	case 1:
-		return nonNilErrs[0]
-	}
-	combined := make([]string, len(nonNilErrs))
-	for i, err := range nonNilErrs {
-		combined[i] = err.Error()
 	}
-	return errors.New(strings.Join(combined, "; "))
+	sort.Strings(destroyErr.Deleted)
+	return destroyErr
 }
 
+// deleteResourceGroup deletes the named resource group, waiting for the
+// deletion to complete. Waiting is abandoned if the call context's dying
+// channel is closed; the deletion may continue in Azure, and it is safe
+// to call deleteResourceGroup again to finish it, as a missing group is
+// not an error.
 func (env *azureEnviron) deleteResourceGroup(ctx context.ProviderCallContext, resourceGroup string) error {
 	client := resources.GroupsClient{env.resources}
-	resultCh, errCh := client.Delete(resourceGroup, nil)
+	resultCh, errCh := client.Delete(resourceGroup, ctx.Dying())
 	result, err := <-resultCh, <-errCh
 	if err != nil {
 		errorutils.HandleCredentialError(err, ctx)
@@ -1692,6 +1925,14 @@ func (env *azureEnviron) getInstanceTypes(ctx context.ProviderCallContext) (map[
 	return instanceTypes, nil
 }
 
+// retiredInstanceTypes returns the VM sizes that may not be used,
+// mapped to their replacements.
+func (env *azureEnviron) retiredInstanceTypes() map[string]string {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	return env.config.retiredInstanceTypes
+}
+
 // getInstanceTypesLocked returns the instance types for Azure, by listing the
 // role sizes available to the subscription.
 func (env *azureEnviron) getInstanceTypesLocked(ctx context.ProviderCallContext) (map[string]instances.InstanceType, error) {
@@ -1741,13 +1982,11 @@ func (env *azureEnviron) maybeGetStorageClient() (internalazurestorage.Client, *
 // getStorageClient queries the storage account key, and uses it to construct
 // a new storage client.
 func (env *azureEnviron) getStorageClient() (internalazurestorage.Client, *storage.Account, error) {
-	env.mu.Lock()
-	defer env.mu.Unlock()
-	storageAccount, err := env.getStorageAccountLocked()
+	storageAccount, err := env.getStorageAccount()
 	if err != nil {
 		return nil, nil, errors.Annotate(err, "getting storage account")
 	}
-	storageAccountKey, err := env.getStorageAccountKeyLocked(
+	storageAccountKey, err := env.getStorageAccountKey(
 		to.String(storageAccount.Name), false,
 	)
 	if err != nil {
@@ -1755,6 +1994,7 @@ func (env *azureEnviron) getStorageClient() (internalazurestorage.Client, *stora
 	}
 	client, err := getStorageClient(
 		env.provider.config.NewStorageClient,
+		env.httpClient,
 		env.storageEndpoint,
 		storageAccount,
 		storageAccountKey,
@@ -1781,7 +2021,12 @@ func (env *azureEnviron) getStorageAccountLocked() (*storage.Account, error) {
 		return *env.storageAccount, nil
 	}
 	client := storage.AccountsClient{env.storage}
+	getStart := time.Now()
 	account, err := client.GetProperties(env.resourceGroup, env.storageAccountName)
+	logger.Debugf(
+		"querying storage account %q took %v",
+		env.storageAccountName, time.Since(getStart),
+	)
 	if err != nil {
 		if isNotFoundResponse(account.Response) {
 			// Remember that the account was not found
@@ -1796,22 +2041,68 @@ func (env *azureEnviron) getStorageAccountLocked() (*storage.Account, error) {
 	return &account, nil
 }
 
-// getStorageAccountKeysLocked returns a storage account key for this
+// getStorageAccountKey returns a storage account key for this
 // environment's storage account. If refresh is true, any cached key
-// will be refreshed. This method assumes that env.mu is held.
-func (env *azureEnviron) getStorageAccountKeyLocked(accountName string, refresh bool) (*storage.AccountKey, error) {
-	if !refresh && env.storageAccountKey != nil {
-		return env.storageAccountKey, nil
+// will be refreshed. This method must not be called with env.mu held;
+// the lock is taken only to read and update the cached key, as fetching
+// the key may back off and retry for some time.
+func (env *azureEnviron) getStorageAccountKey(accountName string, refresh bool) (*storage.AccountKey, error) {
+	env.mu.Lock()
+	key := env.storageAccountKey
+	env.mu.Unlock()
+	if !refresh && key != nil {
+		return key, nil
 	}
-	client := storage.AccountsClient{env.storage}
-	key, err := getStorageAccountKey(client, env.resourceGroup, accountName)
+
+	key, err := env.fetchStorageAccountKey(accountName)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	env.mu.Lock()
 	env.storageAccountKey = key
+	env.mu.Unlock()
+	return key, nil
+}
+
+// fetchStorageAccountKey fetches a storage account key for the named
+// storage account, retrying transient failures. This method must not
+// be called with env.mu held.
+func (env *azureEnviron) fetchStorageAccountKey(accountName string) (*storage.AccountKey, error) {
+	client := storage.AccountsClient{env.storage}
+	var key *storage.AccountKey
+	if err := env.retryTransient("getting storage account key", func() error {
+		var err error
+		key, err = getStorageAccountKey(client, env.resourceGroup, accountName)
+		return err
+	}); err != nil {
+		return nil, errors.Trace(err)
+	}
 	return key, nil
 }
 
+// retryTransient calls f, retrying with backoff for as long as it fails
+// with a transientError, e.g. because Azure is throttling requests. Any
+// other error is returned immediately. This method must not be called
+// with env.mu held.
+func (env *azureEnviron) retryTransient(what string, f func() error) error {
+	return retry.Call(retry.CallArgs{
+		Func: f,
+		IsFatalError: func(err error) bool {
+			_, ok := err.(transientError)
+			return !ok
+		},
+		NotifyFunc: func(err error, attempt int) {
+			logger.Debugf("%s (attempt %d): %v", what, attempt, err)
+		},
+		Attempts:    5,
+		Delay:       retryDelay,
+		MaxDelay:    maxRetryDelay,
+		MaxDuration: maxRetryDuration,
+		BackoffFunc: retry.DoubleDelay,
+		Clock:       env.provider.config.RetryClock,
+	})
+}
+
 // AgentMirror is specified in the tools.HasAgentMirror interface.
 //
 // TODO(axw) 2016-04-11 #1568715
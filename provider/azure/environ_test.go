@@ -5,7 +5,6 @@ package azure_test
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -23,6 +22,7 @@ import (
 	"github.com/Azure/go-autorest/autorest/mocks"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/juju/clock/testclock"
+	"github.com/juju/errors"
 	gitjujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
@@ -165,11 +165,11 @@ func (s *environSuite) SetUpTest(c *gc.C) {
 	}
 
 	vmSizes := []compute.VirtualMachineSize{{
-		Name:                 to.StringPtr("Standard_A1"),
+		Name:                 to.StringPtr("Standard_A1_v2"),
 		NumberOfCores:        to.Int32Ptr(1),
 		OsDiskSizeInMB:       to.Int32Ptr(1047552),
-		ResourceDiskSizeInMB: to.Int32Ptr(71680),
-		MemoryInMB:           to.Int32Ptr(1792),
+		ResourceDiskSizeInMB: to.Int32Ptr(10240),
+		MemoryInMB:           to.Int32Ptr(2048),
 		MaxDataDiskCount:     to.Int32Ptr(2),
 	}, {
 		Name:                 to.StringPtr("Standard_D1"),
@@ -555,7 +555,7 @@ func (s *environSuite) assertStartInstance(c *gc.C, wantedRootDisk *int) {
 	c.Assert(result.VolumeAttachments, gc.HasLen, 0)
 
 	arch := "amd64"
-	mem := uint64(1792)
+	mem := uint64(2048)
 	cpuCores := uint64(1)
 	c.Assert(result.Hardware, jc.DeepEquals, &instance.HardwareCharacteristics{
 		Arch:     &arch,
@@ -567,10 +567,72 @@ func (s *environSuite) assertStartInstance(c *gc.C, wantedRootDisk *int) {
 		imageReference: &quantalImageReference,
 		diskSizeGB:     expectedDiskSize,
 		osProfile:      &s.linuxOsProfile,
-		instanceType:   "Standard_A1",
+		instanceType:   "Standard_A1_v2",
 	})
 }
 
+func (s *environSuite) TestStartInstancePrefersFewestCores(c *gc.C) {
+	// Standard_D12 is ranked cheaper than Standard_G1, but has twice
+	// as many cores as were asked for.
+	vmSizes := []compute.VirtualMachineSize{{
+		Name:           to.StringPtr("Standard_G1"),
+		NumberOfCores:  to.Int32Ptr(2),
+		OsDiskSizeInMB: to.Int32Ptr(1047552),
+		MemoryInMB:     to.Int32Ptr(28672),
+	}, {
+		Name:           to.StringPtr("Standard_D12"),
+		NumberOfCores:  to.Int32Ptr(4),
+		OsDiskSizeInMB: to.Int32Ptr(1047552),
+		MemoryInMB:     to.Int32Ptr(28672),
+	}}
+	s.PatchValue(&s.vmSizes, &compute.VirtualMachineSizeListResult{Value: &vmSizes})
+
+	env := s.openEnviron(c)
+	s.sender = s.startInstanceSenders(false)
+	s.requests = nil
+	args := makeStartInstanceParams(c, s.controllerUUID, "quantal")
+	args.Constraints = constraints.MustParse("cores=2 mem=28G")
+	result, err := env.StartInstance(s.callCtx, args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*result.Hardware.CpuCores, gc.Equals, uint64(2))
+	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
+		imageReference: &quantalImageReference,
+		diskSizeGB:     32,
+		osProfile:      &s.linuxOsProfile,
+		instanceType:   "Standard_G1",
+	})
+}
+
+func (s *environSuite) TestStartInstanceSkipsRetiredInstanceTypes(c *gc.C) {
+	// Standard_A1_v2 is the cheapest size offered, but is retired.
+	env := s.openEnviron(c, testing.Attrs{
+		"retired-instance-types": "Standard_A1_v2",
+	})
+	s.sender = s.startInstanceSenders(false)
+	s.requests = nil
+	result, err := env.StartInstance(s.callCtx, makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*result.Hardware.Mem, gc.Equals, uint64(3584))
+	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
+		imageReference: &quantalImageReference,
+		diskSizeGB:     32,
+		osProfile:      &s.linuxOsProfile,
+		instanceType:   "Standard_D1",
+	})
+}
+
+func (s *environSuite) TestStartInstanceRetiredInstanceType(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{
+		"retired-instance-types": "Standard_A1_v2=Standard_D1",
+	})
+	s.sender = azuretesting.Senders{s.vmSizesSender()}
+	args := makeStartInstanceParams(c, s.controllerUUID, "quantal")
+	args.Constraints = constraints.MustParse("instance-type=A1_v2")
+	_, err := env.StartInstance(s.callCtx, args)
+	c.Assert(err, gc.ErrorMatches, `instance type "A1_v2" is retired; use "Standard_D1" instead`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
 func (s *environSuite) TestStartInstanceNoAuthorizedKeys(c *gc.C) {
 	env := s.openEnviron(c)
 	cfg, err := env.Config().Remove([]string{"authorized-keys"})
@@ -591,7 +653,7 @@ func (s *environSuite) TestStartInstanceNoAuthorizedKeys(c *gc.C) {
 		imageReference: &quantalImageReference,
 		diskSizeGB:     32,
 		osProfile:      &s.linuxOsProfile,
-		instanceType:   "Standard_A1",
+		instanceType:   "Standard_A1_v2",
 	})
 }
 
@@ -663,7 +725,7 @@ func (s *environSuite) testStartInstanceWindows(
 			Settings:                &vmExtensionSettings,
 		},
 		osProfile:    &windowsOsProfile,
-		instanceType: "Standard_A1",
+		instanceType: "Standard_A1_v2",
 	})
 }
 
@@ -692,7 +754,7 @@ func (s *environSuite) TestStartInstanceCentOS(c *gc.C) {
 			Settings:                &vmExtensionSettings,
 		},
 		osProfile:    &s.linuxOsProfile,
-		instanceType: "Standard_A1",
+		instanceType: "Standard_A1_v2",
 	})
 }
 
@@ -736,7 +798,7 @@ func (s *environSuite) TestStartInstanceCommonDeploymentStorageAccount(c *gc.C)
 		imageReference:   &quantalImageReference,
 		diskSizeGB:       32,
 		osProfile:        &s.linuxOsProfile,
-		instanceType:     "Standard_A1",
+		instanceType:     "Standard_A1_v2",
 		unmanagedStorage: true,
 	})
 }
@@ -789,8 +851,165 @@ func (s *environSuite) TestStartInstanceServiceAvailabilitySet(c *gc.C) {
 		imageReference:      &quantalImageReference,
 		diskSizeGB:          32,
 		osProfile:           &s.linuxOsProfile,
-		instanceType:        "Standard_A1",
+		instanceType:        "Standard_A1_v2",
+	})
+}
+
+func (s *environSuite) TestStartInstanceAvailabilitySetDomainCounts(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{
+		"fault-domain-count":  2,
+		"update-domain-count": 10,
+	})
+	unitsDeployed := "mysql/0 wordpress/0"
+	s.vmTags[tags.JujuUnitsDeployed] = &unitsDeployed
+	s.sender = s.startInstanceSenders(false)
+	s.requests = nil
+	params := makeStartInstanceParams(c, s.controllerUUID, "quantal")
+	params.InstanceConfig.Tags[tags.JujuUnitsDeployed] = unitsDeployed
+
+	_, err := env.StartInstance(s.callCtx, params)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
+		availabilitySetName: "mysql",
+		imageReference:      &quantalImageReference,
+		diskSizeGB:          32,
+		osProfile:           &s.linuxOsProfile,
+		instanceType:        "Standard_A1_v2",
+		faultDomainCount:    2,
+		updateDomainCount:   10,
+	})
+}
+
+func (s *environSuite) TestStartInstanceOSDiskCaching(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"os-disk-caching": "ReadOnly"})
+	s.sender = s.startInstanceSenders(false)
+	s.requests = nil
+	_, err := env.StartInstance(s.callCtx, makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
+		imageReference: &quantalImageReference,
+		diskSizeGB:     32,
+		osProfile:      &s.linuxOsProfile,
+		instanceType:   "Standard_A1_v2",
+		osDiskCaching:  compute.ReadOnly,
+	})
+}
+
+func (s *environSuite) TestStartInstanceControllerStaticIPNonController(c *gc.C) {
+	// Only controllers are given a static public IP address.
+	env := s.openEnviron(c, testing.Attrs{"controller-static-ip": true})
+	s.sender = s.startInstanceSenders(false)
+	s.requests = nil
+	_, err := env.StartInstance(s.callCtx, makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertStartInstanceRequests(c, s.requests, assertStartInstanceRequestsParams{
+		imageReference: &quantalImageReference,
+		diskSizeGB:     32,
+		osProfile:      &s.linuxOsProfile,
+		instanceType:   "Standard_A1_v2",
+	})
+}
+
+func (s *environSuite) openEnvironInRegion(c *gc.C, region string, attrs ...testing.Attrs) environs.Environ {
+	spec := fakeCloudSpec()
+	spec.Region = region
+	env, err := environs.Open(s.provider, environs.OpenParams{
+		Cloud:  spec,
+		Config: makeTestModelConfig(c, attrs...),
 	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.sender = azuretesting.Senders{
+		discoverAuthSender(),
+		tokenRefreshSender(),
+	}
+	err = azure.ForceTokenRefresh(env)
+	c.Assert(err, jc.ErrorIsNil)
+	return env
+}
+
+func (s *environSuite) TestFaultDomainCountRegionLimit(c *gc.C) {
+	// japaneast supports only 2 fault domains for the aligned
+	// availability sets used with managed disks.
+	env := s.openEnvironInRegion(c, "japaneast", testing.Attrs{"fault-domain-count": 3})
+	s.sender = s.startInstanceSenders(false)
+	_, err := env.StartInstance(s.callCtx, makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, gc.ErrorMatches, `.*fault-domain-count 3 in region "japaneast" \(expected 1-2\) not valid`)
+	c.Assert(errors.Cause(err), jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *environSuite) TestFaultDomainCountRegionLimitUnmanagedStorage(c *gc.C) {
+	// The region's limit does not apply to models that
+	// use unmanaged storage.
+	resourceTypes := []resources.ProviderResourceType{{
+		ResourceType: to.StringPtr("storageAccounts"),
+	}}
+	providers := []resources.Provider{{
+		Namespace:     to.StringPtr("Microsoft.Storage"),
+		ResourceTypes: &resourceTypes,
+	}}
+	s.commonDeployment.Properties.Providers = &providers
+
+	env := s.openEnvironInRegion(c, "japaneast", testing.Attrs{"fault-domain-count": 3})
+	s.sender = s.startInstanceSenders(false)
+	_, err := env.StartInstance(s.callCtx, makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *environSuite) TestStartInstanceCustomDataTooLarge(c *gc.C) {
+	// Random data doesn't compress well, so the gzipped and encoded
+	// custom data will exceed Azure's limit.
+	userData := "postruncmd:\n  - echo " + utils.RandomString(
+		150000, append(utils.LowerAlpha, utils.Digits...),
+	)
+	env := s.openEnviron(c, testing.Attrs{"cloudinit-userdata": userData})
+
+	// The failed VM is cleaned up, but its deployment was never created.
+	deploymentNotFoundSender := mocks.NewSender()
+	deploymentNotFoundSender.AppendResponse(mocks.NewResponseWithStatus(
+		"deployment not found", http.StatusNotFound,
+	))
+	s.sender = azuretesting.Senders{
+		s.vmSizesSender(),
+		s.makeSender(".*/Canonical/.*/UbuntuServer/skus", s.ubuntuServerSKUs),
+		s.makeSender("/deployments/common", s.commonDeployment),
+		deploymentNotFoundSender,
+	}
+	_, err := env.StartInstance(s.callCtx, makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, gc.ErrorMatches,
+		`creating virtual machine "machine-0": creating OS profile: `+
+			`custom data exceeds Azure limit of 87380 bytes \(got [0-9]+\); `+
+			`reduce the size of the model's cloudinit-userdata`)
+}
+
+func (s *environSuite) TestStartInstanceDeploymentFailureCleansUp(c *gc.C) {
+	env := s.openEnviron(c)
+	senders := s.startInstanceSenders(false)
+	deploymentSender := senders[len(senders)-1].(*azuretesting.MockSender)
+	deploymentSender.SetError(errors.New("no capacity"))
+
+	// The VM never made it into the deployment, but we must still
+	// clean up by name anything that may have been created for it.
+	s.sender = append(senders,
+		s.makeSender(".*/deployments/machine-0/cancel", nil), // POST
+		s.networkInterfacesSender(),
+		s.publicIPAddressesSender(),
+		s.makeSender(".*/virtualMachines/machine-0", nil),                               // DELETE
+		s.makeSender(".*/disks/machine-0", nil),                                         // DELETE
+		s.makeSender(".*/networkSecurityGroups/juju-internal-nsg", makeSecurityGroup()), // GET
+		s.makeSender(".*/deployments/machine-0", nil),                                   // DELETE
+	)
+	s.requests = nil
+	_, err := env.StartInstance(s.callCtx, makeStartInstanceParams(c, s.controllerUUID, "quantal"))
+	c.Assert(err, gc.ErrorMatches, `creating virtual machine "machine-0": creating deployment "machine-0": .*no capacity`)
+	c.Assert(s.sender, gc.HasLen, 0)
+
+	var deletes []string
+	for _, req := range s.requests {
+		if req.Method == "DELETE" {
+			deletes = append(deletes, path.Base(req.URL.Path))
+		}
+	}
+	c.Assert(deletes, jc.DeepEquals, []string{"machine-0", "machine-0", "machine-0"})
 }
 
 // numExpectedStartInstanceRequests is the number of expected requests base
@@ -808,6 +1027,10 @@ type assertStartInstanceRequestsParams struct {
 	needsProviderInit   bool
 	unmanagedStorage    bool
 	instanceType        string
+	faultDomainCount    int32
+	updateDomainCount   int32
+	osDiskCaching       compute.CachingTypes
+	staticPublicIP      bool
 }
 
 func (s *environSuite) assertStartInstanceRequests(
@@ -904,6 +1127,10 @@ func (s *environSuite) assertStartInstanceRequests(
 		privateIPAddress = "192.168.16.4"
 		createCommonResources = true
 	}
+	publicIPAllocationMethod := network.Dynamic
+	if args.staticPublicIP {
+		publicIPAllocationMethod = network.Static
+	}
 	subnetId := fmt.Sprintf(
 		`[concat(resourceId('Microsoft.Network/virtualNetworks', 'juju-internal-network'), '/subnets/%s')]`,
 		subnetName,
@@ -985,10 +1212,17 @@ func (s *environSuite) assertStartInstanceRequests(
 		)
 		var availabilitySetProperties interface{}
 		if !args.unmanagedStorage {
-			availabilitySetProperties = &compute.AvailabilitySetProperties{
+			properties := &compute.AvailabilitySetProperties{
 				Managed:                  to.BoolPtr(true),
 				PlatformFaultDomainCount: to.Int32Ptr(3),
 			}
+			if args.faultDomainCount > 0 {
+				properties.PlatformFaultDomainCount = to.Int32Ptr(args.faultDomainCount)
+			}
+			if args.updateDomainCount > 0 {
+				properties.PlatformUpdateDomainCount = to.Int32Ptr(args.updateDomainCount)
+			}
+			availabilitySetProperties = properties
 		}
 		templateResources = append(templateResources, armtemplates.Resource{
 			APIVersion: computeAPIVersion,
@@ -1004,10 +1238,14 @@ func (s *environSuite) assertStartInstanceRequests(
 		vmDependsOn = append(vmDependsOn, availabilitySetId)
 	}
 
+	osDiskCaching := args.osDiskCaching
+	if osDiskCaching == "" {
+		osDiskCaching = compute.ReadWrite
+	}
 	osDisk := &compute.OSDisk{
 		Name:         to.StringPtr("machine-0"),
 		CreateOption: compute.FromImage,
-		Caching:      compute.ReadWrite,
+		Caching:      osDiskCaching,
 		DiskSizeGB:   to.Int32Ptr(int32(args.diskSizeGB)),
 	}
 	if args.unmanagedStorage {
@@ -1027,7 +1265,7 @@ func (s *environSuite) assertStartInstanceRequests(
 		Location:   "westus",
 		Tags:       to.StringMap(s.vmTags),
 		Properties: &network.PublicIPAddressPropertiesFormat{
-			PublicIPAllocationMethod: network.Dynamic,
+			PublicIPAllocationMethod: publicIPAllocationMethod,
 		},
 	}, {
 		APIVersion: networkAPIVersion,
@@ -1164,8 +1402,9 @@ func (s *environSuite) TestBootstrap(c *gc.C) {
 	ctx := envtesting.BootstrapContext(c)
 	env := prepareForBootstrap(c, ctx, s.provider, &s.sender)
 
-	s.sender = s.initResourceGroupSenders()
-	s.sender = append(s.sender, s.startInstanceSenders(true)...)
+	s.sender = azuretesting.Senders{s.vmSizesSender()}
+	s.sender = append(s.sender, s.initResourceGroupSenders()...)
+	s.sender = append(s.sender, s.startInstanceSendersNoSizes()...)
 	s.requests = nil
 	result, err := env.Bootstrap(
 		ctx, s.callCtx, environs.BootstrapParams{
@@ -1186,7 +1425,41 @@ func (s *environSuite) TestBootstrap(c *gc.C) {
 		imageReference:      &quantalImageReference,
 		diskSizeGB:          32,
 		osProfile:           &s.linuxOsProfile,
+		needsProviderInit:   true,
+		instanceType:        "Standard_D1",
+	})
+}
+
+func (s *environSuite) TestBootstrapControllerStaticIP(c *gc.C) {
+	defer envtesting.DisableFinishBootstrap()()
+
+	ctx := envtesting.BootstrapContext(c)
+	env := prepareForBootstrap(c, ctx, s.provider, &s.sender, testing.Attrs{"controller-static-ip": true})
+
+	s.sender = azuretesting.Senders{s.vmSizesSender()}
+	s.sender = append(s.sender, s.initResourceGroupSenders()...)
+	s.sender = append(s.sender, s.startInstanceSendersNoSizes()...)
+	s.requests = nil
+	_, err := env.Bootstrap(
+		ctx, s.callCtx, environs.BootstrapParams{
+			ControllerConfig:     testing.FakeControllerConfig(),
+			AvailableTools:       makeToolsList("quantal"),
+			BootstrapSeries:      "quantal",
+			BootstrapConstraints: constraints.MustParse("mem=3.5G"),
+		},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(len(s.requests), gc.Equals, numExpectedStartInstanceRequests)
+	s.vmTags[tags.JujuIsController] = to.StringPtr("true")
+	s.assertStartInstanceRequests(c, s.requests[1:], assertStartInstanceRequestsParams{
+		availabilitySetName: "juju-controller",
+		imageReference:      &quantalImageReference,
+		diskSizeGB:          32,
+		osProfile:           &s.linuxOsProfile,
+		needsProviderInit:   true,
 		instanceType:        "Standard_D1",
+		staticPublicIP:      true,
 	})
 }
 
@@ -1216,6 +1489,29 @@ func (s *environSuite) TestBootstrapWithInvalidCredential(c *gc.C) {
 	c.Assert(len(s.requests), gc.Equals, 1)
 }
 
+func (s *environSuite) TestBootstrapNoMatchingInstanceType(c *gc.C) {
+	defer envtesting.DisableFinishBootstrap()()
+
+	ctx := envtesting.BootstrapContext(c)
+	env := prepareForBootstrap(c, ctx, s.provider, &s.sender)
+
+	s.sender = azuretesting.Senders{s.vmSizesSender()}
+	s.requests = nil
+	_, err := env.Bootstrap(
+		ctx, s.callCtx, environs.BootstrapParams{
+			ControllerConfig:     testing.FakeControllerConfig(),
+			AvailableTools:       makeToolsList("quantal"),
+			BootstrapSeries:      "quantal",
+			BootstrapConstraints: constraints.MustParse("mem=1000G"),
+		},
+	)
+	c.Assert(err, gc.ErrorMatches, `checking controller constraints: no instance types in westus matching constraints "mem=1024000M"`)
+
+	// Nothing should have been created.
+	c.Assert(s.requests, gc.HasLen, 1)
+	c.Assert(s.requests[0].Method, gc.Equals, "GET") // vmSizes
+}
+
 func (s *environSuite) TestBootstrapInstanceConstraints(c *gc.C) {
 	if runtime.GOOS == "windows" {
 		c.Skip("bootstrap not supported on Windows")
@@ -1272,8 +1568,9 @@ func (s *environSuite) TestBootstrapWithAutocert(c *gc.C) {
 	ctx := envtesting.BootstrapContext(c)
 	env := prepareForBootstrap(c, ctx, s.provider, &s.sender)
 
-	s.sender = s.initResourceGroupSenders()
-	s.sender = append(s.sender, s.startInstanceSenders(true)...)
+	s.sender = azuretesting.Senders{s.vmSizesSender()}
+	s.sender = append(s.sender, s.initResourceGroupSenders()...)
+	s.sender = append(s.sender, s.startInstanceSendersNoSizes()...)
 	s.requests = nil
 	config := testing.FakeControllerConfig()
 	config["api-port"] = 443
@@ -1298,6 +1595,7 @@ func (s *environSuite) TestBootstrapWithAutocert(c *gc.C) {
 		imageReference:      &quantalImageReference,
 		diskSizeGB:          32,
 		osProfile:           &s.linuxOsProfile,
+		needsProviderInit:   true,
 		instanceType:        "Standard_D1",
 	})
 }
@@ -1337,6 +1635,75 @@ func (s *environSuite) TestAllInstancesIgnoresCommonDeployment(c *gc.C) {
 	c.Assert(instances, gc.HasLen, 0)
 }
 
+func (s *environSuite) TestAllInstancesPaged(c *gc.C) {
+	env := s.openEnviron(c)
+
+	page0 := []resources.DeploymentExtended{makeDeployment("machine-0")}
+	page1 := []resources.DeploymentExtended{makeDeployment("machine-1")}
+	s.sender = azuretesting.Senders{
+		s.makeSender("/deployments", resources.DeploymentListResult{
+			Value:    &page0,
+			NextLink: to.StringPtr("https://example.invalid/deployments-page-1"),
+		}),
+		s.makeSender("/deployments-page-1", resources.DeploymentListResult{
+			Value: &page1,
+		}),
+		s.networkInterfacesSender(),
+		s.publicIPAddressesSender(),
+	}
+
+	instances, err := env.AllInstances(s.callCtx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instances, gc.HasLen, 2)
+	c.Assert(instances[0].Id(), gc.Equals, instance.Id("machine-0"))
+	c.Assert(instances[1].Id(), gc.Equals, instance.Id("machine-1"))
+}
+
+func (s *environSuite) tooManyRequestsSender() *mocks.Sender {
+	sender := mocks.NewSender()
+	sender.AppendResponse(mocks.NewResponseWithStatus(
+		"429 Too Many Requests", http.StatusTooManyRequests,
+	))
+	return sender
+}
+
+func (s *environSuite) TestAllInstancesRateLimited(c *gc.C) {
+	env := s.openEnviron(c)
+
+	page0 := []resources.DeploymentExtended{makeDeployment("machine-0")}
+	page1 := []resources.DeploymentExtended{makeDeployment("machine-1")}
+	s.sender = azuretesting.Senders{
+		s.tooManyRequestsSender(),
+		s.makeSender("/deployments", resources.DeploymentListResult{
+			Value:    &page0,
+			NextLink: to.StringPtr("https://example.invalid/deployments-page-1"),
+		}),
+		s.tooManyRequestsSender(),
+		s.makeSender("/deployments-page-1", resources.DeploymentListResult{
+			Value: &page1,
+		}),
+		s.networkInterfacesSender(),
+		s.publicIPAddressesSender(),
+	}
+
+	instances, err := env.AllInstances(s.callCtx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(instances, gc.HasLen, 2)
+	s.retryClock.CheckCalls(c, []gitjujutesting.StubCall{
+		{"After", []interface{}{5 * time.Second}},
+		{"After", []interface{}{5 * time.Second}},
+	})
+}
+
+func (s *environSuite) TestAllInstancesRateLimitedRetriesExhausted(c *gc.C) {
+	env := s.openEnviron(c)
+	for i := 0; i < 5; i++ {
+		s.sender = append(s.sender, s.tooManyRequestsSender())
+	}
+	_, err := env.AllInstances(s.callCtx)
+	c.Assert(err, gc.ErrorMatches, "attempt count exceeded: .*")
+}
+
 func (s *environSuite) TestStopInstancesNotFound(c *gc.C) {
 	env := s.openEnviron(c)
 	sender0 := mocks.NewSender()
@@ -1525,6 +1892,64 @@ func (s *environSuite) TestStopInstancesStorageAccountKeysError(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "getting storage account key:.*blargh")
 }
 
+func (s *environSuite) TestStopInstancesStorageAccountKeysRetry(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = azuretesting.Senders{
+		s.makeSender("/deployments/machine-0", s.deployment), // Cancel
+		s.storageAccountSender(),
+	}
+	for i := 0; i < 5; i++ {
+		sender := mocks.NewSender()
+		sender.AppendResponse(mocks.NewResponseWithStatus(
+			"503 Service Unavailable", http.StatusServiceUnavailable,
+		))
+		s.sender = append(s.sender, sender)
+	}
+	err := env.StopInstances(s.callCtx, "machine-0")
+	c.Assert(err, gc.ErrorMatches,
+		"getting storage account key: attempt count exceeded: listing storage account keys: .*")
+
+	s.retryClock.CheckCalls(c, []gitjujutesting.StubCall{
+		{"After", []interface{}{5 * time.Second}},
+		{"After", []interface{}{10 * time.Second}},
+		{"After", []interface{}{20 * time.Second}},
+		{"After", []interface{}{40 * time.Second}},
+	})
+}
+
+func (s *environSuite) TestStopInstancesStorageAccountKeysTransientError(c *gc.C) {
+	env := s.openEnviron(c)
+	nic0 := makeNetworkInterface("nic-0", "machine-0", makeIPConfiguration("192.168.0.4"))
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/deployments/machine-0/cancel", nil), // POST
+		s.storageAccountSender(),
+	}
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		sender := mocks.NewSender()
+		sender.AppendResponse(mocks.NewResponseWithStatus(http.StatusText(status), status))
+		s.sender = append(s.sender, sender)
+	}
+	s.sender = append(s.sender,
+		s.storageAccountKeysSender(),
+		s.networkInterfacesSender(nic0),
+		s.publicIPAddressesSender(),
+		s.makeSender(".*/virtualMachines/machine-0", nil),                               // DELETE
+		s.makeSender(".*/networkSecurityGroups/juju-internal-nsg", makeSecurityGroup()), // GET
+		s.makeSender(".*/networkInterfaces/nic-0", nil),                                 // DELETE
+		s.makeSender(".*/deployments/machine-0", nil),                                   // DELETE
+	)
+	machine0Blob := azuretesting.MockStorageBlob{Name_: "machine-0"}
+	s.osvhdsContainer.Blobs_ = []azurestorage.Blob{&machine0Blob}
+
+	err := env.StopInstances(s.callCtx, "machine-0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.retryClock.CheckCalls(c, []gitjujutesting.StubCall{
+		{"After", []interface{}{5 * time.Second}},
+		{"After", []interface{}{10 * time.Second}},
+	})
+}
+
 func (s *environSuite) TestConstraintsValidatorUnsupported(c *gc.C) {
 	validator := s.constraintsValidator(c)
 	unsupported, err := validator.Validate(constraints.MustParse(
@@ -1542,7 +1967,7 @@ func (s *environSuite) TestConstraintsValidatorVocabulary(c *gc.C) {
 	)
 	_, err = validator.Validate(constraints.MustParse("instance-type=t1.micro"))
 	c.Assert(err, gc.ErrorMatches,
-		"invalid constraint value: instance-type=t1.micro\nvalid values are: \\[A1 D1 D2 Standard_A1 Standard_D1 Standard_D2\\]",
+		"invalid constraint value: instance-type=t1.micro\nvalid values are: \\[A1_v2 D1 D2 Standard_A1_v2 Standard_D1 Standard_D2\\]",
 	)
 }
 
@@ -1556,9 +1981,121 @@ func (s *environSuite) TestConstraintsValidatorMerge(c *gc.C) {
 	c.Assert(cons.String(), gc.Equals, "instance-type=D1")
 }
 
-func (s *environSuite) constraintsValidator(c *gc.C) constraints.Validator {
+func (s *environSuite) TestConstraintsValidatorRetiredInstanceType(c *gc.C) {
+	validator := s.constraintsValidator(c, testing.Attrs{
+		"retired-instance-types": "Standard_D1=Standard_D1_v2",
+	})
+	_, err := validator.Validate(constraints.MustParse("instance-type=D1"))
+	c.Assert(err, gc.ErrorMatches, `instance type "D1" is retired; use "Standard_D1_v2" instead`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	_, err = validator.Validate(constraints.MustParse("instance-type=Standard_D1"))
+	c.Assert(err, gc.ErrorMatches, `instance type "Standard_D1" is retired; use "Standard_D1_v2" instead`)
+	_, err = validator.Validate(constraints.MustParse("instance-type=D2"))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *environSuite) TestConstraintsValidatorRetiredInstanceTypeNoReplacement(c *gc.C) {
+	validator := s.constraintsValidator(c, testing.Attrs{
+		"retired-instance-types": "D1",
+	})
+	_, err := validator.Validate(constraints.MustParse("instance-type=Standard_D1"))
+	c.Assert(err, gc.ErrorMatches, `instance type "Standard_D1" is retired`)
+}
+
+func (s *environSuite) TestConstraintsValidatorAzureRetiredInstanceTypes(c *gc.C) {
+	// Azure still lists some sizes that it has retired.
+	vmSizes := append(*s.vmSizes.Value, compute.VirtualMachineSize{
+		Name:           to.StringPtr("Standard_A1"),
+		NumberOfCores:  to.Int32Ptr(1),
+		OsDiskSizeInMB: to.Int32Ptr(1047552),
+		MemoryInMB:     to.Int32Ptr(1792),
+	})
+	s.PatchValue(&s.vmSizes, &compute.VirtualMachineSizeListResult{Value: &vmSizes})
+	validator := s.constraintsValidator(c)
+	_, err := validator.Validate(constraints.MustParse("instance-type=A1"))
+	c.Assert(err, gc.ErrorMatches, `instance type "A1" is retired; use "Standard_A1_v2" instead`)
+}
+
+func (s *environSuite) TestPrecheckInstanceRetiredInstanceType(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{
+		"retired-instance-types": "Standard_D1=Standard_D1_v2",
+	})
+	err := env.PrecheckInstance(s.callCtx, environs.PrecheckInstanceParams{
+		Series:      "quantal",
+		Constraints: constraints.MustParse("instance-type=D1"),
+	})
+	c.Assert(err, gc.ErrorMatches, `instance type "D1" is retired; use "Standard_D1_v2" instead`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *environSuite) TestPrecheckInstanceInvalidInstanceType(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = azuretesting.Senders{s.vmSizesSender()}
+	err := env.PrecheckInstance(s.callCtx, environs.PrecheckInstanceParams{
+		Series:      "quantal",
+		Constraints: constraints.MustParse("instance-type=t1.micro"),
+	})
+	c.Assert(err, gc.ErrorMatches, `invalid instance type "t1.micro"`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *environSuite) TestPrecheckInstanceUnknownPlacement(c *gc.C) {
+	env := s.openEnviron(c)
+	err := env.PrecheckInstance(s.callCtx, environs.PrecheckInstanceParams{
+		Series:    "quantal",
+		Placement: "zone=westus-1",
+	})
+	c.Assert(err, gc.ErrorMatches, `unknown placement directive: zone=westus-1`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *environSuite) TestInstanceTypes(c *gc.C) {
+	env := s.openEnviron(c)
+	s.sender = azuretesting.Senders{s.vmSizesSender()}
+	fetcher, ok := env.(environs.InstanceTypesFetcher)
+	c.Assert(ok, jc.IsTrue)
+	result, err := fetcher.InstanceTypes(s.callCtx, constraints.Value{})
+	c.Assert(err, jc.ErrorIsNil)
+	var names []string
+	for _, instanceType := range result.InstanceTypes {
+		names = append(names, instanceType.Name)
+	}
+	// Aliases are omitted, and the types are sorted by memory.
+	c.Assert(names, jc.DeepEquals, []string{"Standard_A1_v2", "Standard_D1", "Standard_D2"})
+}
+
+func (s *environSuite) TestInstanceTypesRetired(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{
+		"retired-instance-types": "Standard_A1_v2",
+	})
+	s.sender = azuretesting.Senders{s.vmSizesSender()}
+	fetcher, ok := env.(environs.InstanceTypesFetcher)
+	c.Assert(ok, jc.IsTrue)
+	result, err := fetcher.InstanceTypes(s.callCtx, constraints.Value{})
+	c.Assert(err, jc.ErrorIsNil)
+	var names []string
+	for _, instanceType := range result.InstanceTypes {
+		names = append(names, instanceType.Name)
+	}
+	c.Assert(names, jc.DeepEquals, []string{"Standard_D1", "Standard_D2"})
+}
+
+func (s *environSuite) TestInstanceTypesConstraints(c *gc.C) {
 	env := s.openEnviron(c)
 	s.sender = azuretesting.Senders{s.vmSizesSender()}
+	fetcher := env.(environs.InstanceTypesFetcher)
+	result, err := fetcher.InstanceTypes(s.callCtx, constraints.MustParse("cores=2"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.InstanceTypes, gc.HasLen, 1)
+	c.Assert(result.InstanceTypes[0].Name, gc.Equals, "Standard_D2")
+
+	_, err = fetcher.InstanceTypes(s.callCtx, constraints.MustParse("cores=64"))
+	c.Assert(err, gc.ErrorMatches, `no instance types in westus matching constraints "cores=64"`)
+}
+
+func (s *environSuite) constraintsValidator(c *gc.C, attrs ...testing.Attrs) constraints.Validator {
+	env := s.openEnviron(c, attrs...)
+	s.sender = azuretesting.Senders{s.vmSizesSender()}
 	validator, err := env.ConstraintsValidator(context.NewCloudCallContext())
 	c.Assert(err, jc.ErrorIsNil)
 	return validator
@@ -1597,6 +2134,35 @@ func (s *environSuite) TestDestroyHostedModelWithInvalidCredential(c *gc.C) {
 	c.Assert(s.requests[0].Method, gc.Equals, "DELETE")
 }
 
+func (s *environSuite) TestDestroyHostedModelDying(c *gc.C) {
+	env := s.openEnviron(c, testing.Attrs{"controller-uuid": utils.MustNewUUID().String()})
+	dying := make(chan struct{})
+	s.callCtx.DyingFunc = func() <-chan struct{} {
+		return dying
+	}
+	// The resource group deletion is accepted, but does not complete;
+	// closing the dying channel abandons the wait for it.
+	s.sender = azuretesting.Senders{
+		autorest.SenderFunc(func(req *http.Request) (*http.Response, error) {
+			close(dying)
+			resp := mocks.NewResponseWithStatus("202 Accepted", http.StatusAccepted)
+			resp.Header.Set("Location", "https://management.azure.com/operationresults/deletion")
+			resp.Request = req
+			return resp, nil
+		}),
+	}
+	err := env.Destroy(s.callCtx)
+	c.Assert(err, gc.ErrorMatches, `deleting resource group "juju-testmodel-model-.*": .*canceled.*`)
+	c.Assert(s.requests, gc.HasLen, 1)
+	c.Assert(s.requests[0].Method, gc.Equals, "DELETE")
+
+	destroyErr, ok := errors.Cause(err).(*azure.DestroyError)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(destroyErr.Deleted, gc.HasLen, 0)
+	c.Assert(destroyErr.Incomplete, gc.HasLen, 1)
+	c.Assert(destroyErr.Incomplete["juju-testmodel-model-"+testing.ModelTag.Id()], gc.NotNil)
+}
+
 func (s *environSuite) TestDestroyController(c *gc.C) {
 	groups := []resources.Group{{
 		Name: to.StringPtr("group1"),
@@ -1648,6 +2214,28 @@ func (s *environSuite) TestDestroyControllerWithInvalidCredential(c *gc.C) {
 	))
 }
 
+func (s *environSuite) TestDestroyControllerRateLimited(c *gc.C) {
+	groups := []resources.Group{{
+		Name: to.StringPtr("group1"),
+	}}
+	result := resources.GroupListResult{Value: &groups}
+
+	env := s.openEnviron(c)
+	s.requests = nil
+	s.sender = azuretesting.Senders{
+		s.tooManyRequestsSender(),
+		s.makeSender(".*/resourcegroups", result),     // GET
+		s.makeSender(".*/resourcegroups/group1", nil), // DELETE
+	}
+	err := env.DestroyController(s.callCtx, s.controllerUUID)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.requests, gc.HasLen, 3)
+	c.Assert(s.requests[2].Method, gc.Equals, "DELETE")
+	s.retryClock.CheckCalls(c, []gitjujutesting.StubCall{
+		{"After", []interface{}{5 * time.Second}},
+	})
+}
+
 func (s *environSuite) TestDestroyControllerErrors(c *gc.C) {
 	groups := []resources.Group{
 		{Name: to.StringPtr("group1")},
@@ -1693,6 +2281,45 @@ func (s *environSuite) TestDestroyControllerErrors(c *gc.C) {
 	c.Check(destroyErr, gc.ErrorMatches, ".*bar.*")
 }
 
+func (s *environSuite) TestDestroyControllerPartialFailure(c *gc.C) {
+	groups := []resources.Group{
+		{Name: to.StringPtr("group1")},
+		{Name: to.StringPtr("group2")},
+	}
+	result := resources.GroupListResult{Value: &groups}
+
+	errorSender := &azuretesting.MockSender{
+		Sender:      mocks.NewSender(),
+		PathPattern: ".*/resourcegroups/group2.*",
+	}
+	errorSender.SetError(errors.New("foo"))
+	okSender := s.makeSender(".*/resourcegroups/group1", nil)
+
+	// Groups are deleted concurrently, so dispatch on the group name.
+	deleteSender := autorest.SenderFunc(func(req *http.Request) (*http.Response, error) {
+		if path.Base(req.URL.Path) == "group2" {
+			return errorSender.Do(req)
+		}
+		return okSender.Do(req)
+	})
+
+	env := s.openEnviron(c)
+	s.requests = nil
+	s.sender = azuretesting.Senders{
+		s.makeSender(".*/resourcegroups", result), // GET
+		deleteSender, // DELETE
+		deleteSender, // DELETE
+	}
+	err := env.DestroyController(s.callCtx, s.controllerUUID)
+	c.Assert(err, gc.ErrorMatches, `deleting resource group "group2":.*foo.* \(deleted resource groups: group1\)`)
+
+	destroyErr, ok := errors.Cause(err).(*azure.DestroyError)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(destroyErr.Deleted, jc.DeepEquals, []string{"group1"})
+	c.Assert(destroyErr.Incomplete, gc.HasLen, 1)
+	c.Assert(destroyErr.Incomplete["group2"], gc.ErrorMatches, `deleting resource group "group2":.*foo.*`)
+}
+
 func (s *environSuite) TestInstanceInformation(c *gc.C) {
 	env := s.openEnviron(c)
 	s.sender = s.startInstanceSenders(false)
@@ -4,6 +4,9 @@
 package azure
 
 import (
+	"crypto/tls"
+	"net/http"
+
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/juju/clock"
 	"github.com/juju/errors"
@@ -33,9 +36,16 @@ var logger = loggo.GetLogger("juju.provider.azure")
 type ProviderConfig struct {
 	// Sender is the autorest.Sender that will be used by Azure
 	// clients. If sender is nil, the default HTTP client sender
-	// will be used.
+	// will be used. The registered provider uses a sender that
+	// requires TLS 1.2 or later.
 	Sender autorest.Sender
 
+	// NewSender, if non-nil, is used to construct the HTTP client
+	// for a model's Azure clients, including its blob storage
+	// client, from the model's TLS settings. Sender is used for
+	// everything else, and for models if NewSender is nil.
+	NewSender func(*tls.Config) *http.Client
+
 	// RequestInspector will be used to inspect Azure requests
 	// if it is non-nil.
 	RequestInspector autorest.PrepareDecorator
@@ -4,6 +4,7 @@
 package azure_test
 
 import (
+	"crypto/tls"
 	"net/http"
 	"time"
 
@@ -122,3 +123,13 @@ func newProvider(c *gc.C, config azure.ProviderConfig) environs.EnvironProvider
 	c.Assert(err, jc.ErrorIsNil)
 	return environProvider
 }
+
+func (s *environProviderSuite) TestSecureSender(c *gc.C) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	client := azure.NewSecureSender(tlsConfig)
+	transport, ok := client.Transport.(*http.Transport)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(transport.TLSClientConfig, gc.Equals, tlsConfig)
+	c.Assert(transport.DisableKeepAlives, jc.IsFalse)
+	c.Assert(transport.Proxy, gc.NotNil)
+}
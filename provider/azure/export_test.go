@@ -8,6 +8,10 @@ import (
 	"github.com/juju/juju/storage"
 )
 
+var (
+	NewSecureSender = newSecureSender
+)
+
 func ForceVolumeSourceTokenRefresh(vs storage.VolumeSource) error {
 	return ForceTokenRefresh(vs.(*azureVolumeSource).env)
 }
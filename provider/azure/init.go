@@ -6,6 +6,7 @@ package azure
 import (
 	"github.com/juju/clock"
 	"github.com/juju/errors"
+	"github.com/juju/utils"
 	"github.com/juju/utils/ssh"
 
 	"github.com/juju/juju/environs"
@@ -30,6 +31,8 @@ func NewProvider(config ProviderConfig) (environs.CloudEnvironProvider, error) {
 
 func init() {
 	environProvider, err := NewProvider(ProviderConfig{
+		Sender:                     newSecureSender(utils.SecureTLSConfig()),
+		NewSender:                  newSecureSender,
 		NewStorageClient:           azurestorage.NewClient,
 		RetryClock:                 &clock.WallClock,
 		RandomWindowsAdminPassword: randomAdminPassword,
@@ -4,6 +4,8 @@
 package azure
 
 import (
+	"sort"
+
 	"github.com/juju/errors"
 
 	"github.com/juju/juju/constraints"
@@ -14,25 +16,45 @@ import (
 
 var _ environs.InstanceTypesFetcher = (*azureEnviron)(nil)
 
-// InstanceTypes implements InstanceTypesFetcher
+// InstanceTypes implements InstanceTypesFetcher. The matching instance
+// types are returned sorted by memory, then name, so that the result is
+// stable.
 func (env *azureEnviron) InstanceTypes(ctx context.ProviderCallContext, c constraints.Value) (instances.InstanceTypesWithCostMetadata, error) {
 	types, err := env.getInstanceTypes(ctx)
 	if err != nil {
 		return instances.InstanceTypesWithCostMetadata{}, errors.Trace(err)
 	}
-	result := make([]instances.InstanceType, len(types))
-	i := 0
-	for _, iType := range types {
-		result[i] = iType
-		i++
+	types = withoutRetiredInstanceTypes(types, env.retiredInstanceTypes())
+	result := make([]instances.InstanceType, 0, len(types))
+	for name, iType := range types {
+		if name != iType.Name {
+			// Skip the aliases for standard
+			// sizes, to avoid duplicates.
+			continue
+		}
+		result = append(result, iType)
 	}
-	result, err = instances.MatchingInstanceTypes(result, "", c)
+	result, err = instances.MatchingInstanceTypes(result, env.location, c)
 	if err != nil {
 		return instances.InstanceTypesWithCostMetadata{}, errors.Trace(err)
 	}
+	sort.Sort(byMemory(result))
 
 	return instances.InstanceTypesWithCostMetadata{
 		InstanceTypes: result,
 		CostUnit:      "",
 		CostCurrency:  "USD"}, nil
 }
+
+// byMemory is used to sort a slice of instance types by memory,
+// and then by name.
+type byMemory []instances.InstanceType
+
+func (s byMemory) Len() int      { return len(s) }
+func (s byMemory) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byMemory) Less(i, j int) bool {
+	if s[i].Mem != s[j].Mem {
+		return s[i].Mem < s[j].Mem
+	}
+	return s[i].Name < s[j].Name
+}
@@ -4,6 +4,9 @@
 package azure
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/Azure/azure-sdk-for-go/arm/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/juju/errors"
@@ -17,6 +20,73 @@ import (
 
 const defaultMem = 1024 // 1GiB
 
+// defaultRetiredInstanceTypes maps VM sizes that Azure has retired to
+// the size recommended in their place. Retired sizes are never chosen,
+// and may not be asked for, even if they are still listed by the VM
+// sizes API. The retired-instance-types model config adds to these.
+var defaultRetiredInstanceTypes = map[string]string{
+	"Basic_A0":    "Standard_A1_v2",
+	"Basic_A1":    "Standard_A1_v2",
+	"Basic_A2":    "Standard_A2_v2",
+	"Basic_A3":    "Standard_A4_v2",
+	"Basic_A4":    "Standard_A8_v2",
+	"Standard_A0": "Standard_A1_v2",
+	"Standard_A1": "Standard_A1_v2",
+	"Standard_A2": "Standard_A2_v2",
+	"Standard_A3": "Standard_A4_v2",
+	"Standard_A4": "Standard_A8_v2",
+	"Standard_A5": "Standard_A2m_v2",
+	"Standard_A6": "Standard_A4m_v2",
+	"Standard_A7": "Standard_A8m_v2",
+}
+
+// retiredInstanceType returns the replacement for the named instance
+// type, and true if the instance type is one of the given retired
+// instance types. The name may be an alias without the "Standard_"
+// prefix, and the retired instance types may be given either way too.
+// The replacement is empty if none was specified.
+func retiredInstanceType(retired map[string]string, name string) (string, bool) {
+	for _, candidate := range []string{
+		name,
+		"Standard_" + name,
+		strings.TrimPrefix(name, "Standard_"),
+	} {
+		if replacement, ok := retired[candidate]; ok {
+			return replacement, true
+		}
+	}
+	return "", false
+}
+
+// retiredInstanceTypeError returns an error stating that the named
+// instance type is retired, suggesting the replacement if there is one.
+func retiredInstanceTypeError(name, replacement string) error {
+	if replacement == "" {
+		return errors.NewNotValid(nil, fmt.Sprintf(
+			"instance type %q is retired", name,
+		))
+	}
+	return errors.NewNotValid(nil, fmt.Sprintf(
+		"instance type %q is retired; use %q instead", name, replacement,
+	))
+}
+
+// withoutRetiredInstanceTypes returns the given instance types, less
+// any that are retired.
+func withoutRetiredInstanceTypes(
+	instanceTypes map[string]instances.InstanceType,
+	retired map[string]string,
+) map[string]instances.InstanceType {
+	result := make(map[string]instances.InstanceType, len(instanceTypes))
+	for name, instanceType := range instanceTypes {
+		if _, ok := retiredInstanceType(retired, instanceType.Name); ok {
+			continue
+		}
+		result[name] = instanceType
+	}
+	return result
+}
+
 // newInstanceType creates an InstanceType based on a VirtualMachineSize.
 func newInstanceType(size compute.VirtualMachineSize) instances.InstanceType {
 	// We're not doing real costs for now; just made-up, relative
@@ -28,28 +98,27 @@ func newInstanceType(size compute.VirtualMachineSize) instances.InstanceType {
 	// Likewise for GS and G. We put the premium storage variants
 	// directly after their non-premium counterparts.
 	machineSizeCost := []string{
-		"Standard_A0",
-		"Standard_A1",
+		"Standard_A1_v2",
 		"Standard_D1",
 		"Standard_DS1",
 		"Standard_D1_v2",
-		"Standard_A2",
+		"Standard_A2_v2",
 		"Standard_D2",
 		"Standard_DS2",
 		"Standard_D2_v2",
+		"Standard_A2m_v2",
 		"Standard_D11",
 		"Standard_DS11",
 		"Standard_D11_v2",
-		"Standard_A3",
+		"Standard_A4_v2",
 		"Standard_D3",
 		"Standard_DS3",
 		"Standard_D3_v2",
 		"Standard_D12",
 		"Standard_DS12",
 		"Standard_D12_v2",
-		"Standard_A5", // Yes, A5 is cheaper than A4.
-		"Standard_A4",
-		"Standard_A6",
+		"Standard_A4m_v2",
+		"Standard_A8_v2",
 		"Standard_G1",
 		"Standard_GS1",
 		"Standard_D4",
@@ -58,7 +127,7 @@ func newInstanceType(size compute.VirtualMachineSize) instances.InstanceType {
 		"Standard_D13",
 		"Standard_DS13",
 		"Standard_D13_v2",
-		"Standard_A7",
+		"Standard_A8m_v2",
 		"Standard_A10",
 		"Standard_G2",
 		"Standard_GS2",
@@ -75,17 +144,6 @@ func newInstanceType(size compute.VirtualMachineSize) instances.InstanceType {
 		"Standard_GS4",
 		"Standard_GS5",
 		"Standard_G5",
-
-		// Basic instances are less capable than standard
-		// ones, so we don't want to be providing them as
-		// a default. This is achieved by costing them at
-		// a higher price, even though they are cheaper
-		// in reality.
-		"Basic_A0",
-		"Basic_A1",
-		"Basic_A2",
-		"Basic_A3",
-		"Basic_A4",
 	}
 
 	// Anything not in the list is more expensive that is in the list.
@@ -152,9 +210,46 @@ func findInstanceSpec(
 		instanceTypes = append(instanceTypes, instanceType)
 	}
 	constraint.Constraints = defaultToBaselineSpec(constraint.Constraints)
+	instanceTypes = preferFewestCores(instanceTypes, constraint.Region, constraint.Constraints)
 	return instances.FindInstanceSpec(images, constraint, instanceTypes)
 }
 
+// preferFewestCores narrows the given instance types down to those with
+// the fewest CPU cores that still satisfy the constraints, if the
+// constraints specify a number of cores. The remaining instance types are
+// then chosen between by cost as usual.
+//
+// Our instance type costs are only relative, so without this a "cores=N"
+// constraint may be satisfied by a type with many more cores and much more
+// memory than was asked for, simply because that family is ranked cheaper.
+func preferFewestCores(
+	instanceTypes []instances.InstanceType,
+	region string,
+	cons constraints.Value,
+) []instances.InstanceType {
+	if !cons.HasCpuCores() || cons.HasInstanceType() {
+		return instanceTypes
+	}
+	matching, err := instances.MatchingInstanceTypes(instanceTypes, region, cons)
+	if err != nil || len(matching) == 0 {
+		// Leave it to FindInstanceSpec to report the failure.
+		return instanceTypes
+	}
+	fewestCores := matching[0].CpuCores
+	for _, instanceType := range matching[1:] {
+		if instanceType.CpuCores < fewestCores {
+			fewestCores = instanceType.CpuCores
+		}
+	}
+	var result []instances.InstanceType
+	for _, instanceType := range matching {
+		if instanceType.CpuCores == fewestCores {
+			result = append(result, instanceType)
+		}
+	}
+	return result
+}
+
 func constraintHasArch(constraint *instances.InstanceConstraint, arch string) bool {
 	for _, constraintArch := range constraint.Arches {
 		if constraintArch == arch {
@@ -4,6 +4,8 @@
 package azurestorage
 
 import (
+	"net/http"
+
 	"github.com/Azure/azure-sdk-for-go/storage"
 	"github.com/juju/errors"
 )
@@ -55,15 +57,24 @@ type Blob interface {
 type NewClientFunc func(
 	accountName, accountKey, blobServiceBaseURL, apiVersion string,
 	useHTTPS bool,
+	httpClient *http.Client,
 ) (Client, error)
 
 // NewClient returns a Client that is backed by a storage.Client created with
-// storage.NewClient
-func NewClient(accountName, accountKey, blobServiceBaseURL, apiVersion string, useHTTPS bool) (Client, error) {
+// storage.NewClient. If httpClient is non-nil, it is used to send requests
+// to the storage service.
+func NewClient(
+	accountName, accountKey, blobServiceBaseURL, apiVersion string,
+	useHTTPS bool,
+	httpClient *http.Client,
+) (Client, error) {
 	client, err := storage.NewClient(accountName, accountKey, blobServiceBaseURL, apiVersion, useHTTPS)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if httpClient != nil {
+		client.HTTPClient = httpClient
+	}
 	return clientWrapper{client}, nil
 }
 
@@ -5,6 +5,7 @@ package azure
 
 import (
 	"fmt"
+	"net/http"
 	"path"
 	"strings"
 	"sync"
@@ -69,6 +70,7 @@ type azureStorageProvider struct {
 }
 
 var _ storage.Provider = (*azureStorageProvider)(nil)
+var _ storage.PoolDefaulter = (*azureStorageProvider)(nil)
 
 var azureStorageConfigFields = schema.Fields{
 	accountTypeAttr: schema.OneOf(
@@ -77,11 +79,13 @@ var azureStorageConfigFields = schema.Fields{
 	),
 }
 
+var azureStorageConfigDefaults = schema.Defaults{
+	accountTypeAttr: accountTypeStandardLRS,
+}
+
 var azureStorageConfigChecker = schema.FieldMap(
 	azureStorageConfigFields,
-	schema.Defaults{
-		accountTypeAttr: accountTypeStandardLRS,
-	},
+	azureStorageConfigDefaults,
 )
 
 type azureStorageConfig struct {
@@ -137,6 +141,15 @@ func (e *azureStorageProvider) DefaultPools() []*storage.Config {
 	return []*storage.Config{premiumPool}
 }
 
+// DefaultPoolAttributes is part of the storage.PoolDefaulter interface.
+func (e *azureStorageProvider) DefaultPoolAttributes() map[string]interface{} {
+	attrs := make(map[string]interface{}, len(azureStorageConfigDefaults))
+	for k, v := range azureStorageConfigDefaults {
+		attrs[k] = v
+	}
+	return attrs
+}
+
 // VolumeSource is part of the Provider interface.
 func (e *azureStorageProvider) VolumeSource(cfg *storage.Config) (storage.VolumeSource, error) {
 	// Check to see if the environment has a storage account,
@@ -893,6 +906,7 @@ func blobVolumeId(blob internalazurestorage.Blob) (string, bool) {
 // and a constructor.
 func getStorageClient(
 	newClient internalazurestorage.NewClientFunc,
+	httpClient *http.Client,
 	storageEndpoint string,
 	storageAccount *armstorage.Account,
 	storageAccountKey *armstorage.AccountKey,
@@ -905,6 +919,7 @@ func getStorageClient(
 		storageEndpoint,
 		azurestorage.DefaultAPIVersion,
 		useHTTPS,
+		httpClient,
 	)
 }
 
@@ -917,9 +932,15 @@ func getStorageAccountKey(
 	listKeysResult, err := client.ListKeys(resourceGroup, accountName)
 	if err != nil {
 		if isNotFoundResponse(listKeysResult.Response) {
-			return nil, errors.NewNotFound(err, "storage account keys not found")
+			return nil, errors.NewNotFound(err, fmt.Sprintf(
+				"storage account %q not found", accountName,
+			))
+		}
+		err = errors.Annotate(err, "listing storage account keys")
+		if isTransientResponse(listKeysResult.Response) {
+			return nil, transientError{err}
 		}
-		return nil, errors.Annotate(err, "listing storage account keys")
+		return nil, err
 	}
 	if listKeysResult.Keys == nil {
 		return nil, errors.NotFoundf("storage account keys")
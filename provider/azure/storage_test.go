@@ -4,6 +4,7 @@
 package azure_test
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/arm/disk"
 	armstorage "github.com/Azure/azure-sdk-for-go/arm/storage"
 	azurestorage "github.com/Azure/azure-sdk-for-go/storage"
+	"github.com/Azure/go-autorest/autorest"
 	autorestazure "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/mocks"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -168,6 +170,20 @@ func (s *storageSuite) TestScope(c *gc.C) {
 	c.Assert(s.provider.Scope(), gc.Equals, storage.ScopeEnviron)
 }
 
+func (s *storageSuite) TestDefaultPoolAttributes(c *gc.C) {
+	defaulter, ok := s.provider.(storage.PoolDefaulter)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(defaulter.DefaultPoolAttributes(), jc.DeepEquals, map[string]interface{}{
+		"account-type": "Standard_LRS",
+	})
+
+	// The defaults returned are a copy of the config schema's defaults.
+	defaulter.DefaultPoolAttributes()["account-type"] = "Premium_LRS"
+	c.Assert(defaulter.DefaultPoolAttributes(), jc.DeepEquals, map[string]interface{}{
+		"account-type": "Standard_LRS",
+	})
+}
+
 func (s *storageSuite) TestCreateVolumes(c *gc.C) {
 	makeVolumeParams := func(volume, machine string, size uint64) storage.VolumeParams {
 		return storage.VolumeParams{
@@ -549,13 +565,59 @@ func (s *storageSuite) TestListVolumesLegacy(c *gc.C) {
 	s.storageClient.CheckCallNames(c, "NewClient", "GetContainerReference")
 	s.storageClient.CheckCall(
 		c, 0, "NewClient", storageAccountName, fakeStorageAccountKey,
-		"storage.azurestack.local", azurestorage.DefaultAPIVersion, true,
+		"storage.azurestack.local", azurestorage.DefaultAPIVersion, true, (*http.Client)(nil),
 	)
 	s.storageClient.CheckCall(c, 1, "GetContainerReference", "datavhds")
 	s.datavhdsContainer.CheckCallNames(c, "Blobs")
 	c.Assert(volumeIds, jc.DeepEquals, []string{"volume-1", "volume-0"})
 }
 
+func (s *storageSuite) TestListVolumesLegacyModelTLSConfig(c *gc.C) {
+	var tlsConfig *tls.Config
+	var httpClient *http.Client
+	envProvider := newProvider(c, azure.ProviderConfig{
+		NewSender: func(config *tls.Config) *http.Client {
+			tlsConfig = config
+			httpClient = &http.Client{Transport: senderTransport{&s.sender}}
+			return httpClient
+		},
+		NewStorageClient: s.storageClient.NewClient,
+	})
+	env := openEnviron(c, envProvider, &s.sender, testing.Attrs{
+		"tls-min-version":   "1.1",
+		"tls-cipher-suites": "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	})
+	c.Assert(tlsConfig, gc.NotNil)
+	c.Assert(tlsConfig.MinVersion, gc.Equals, uint16(tls.VersionTLS11))
+	c.Assert(tlsConfig.CipherSuites, jc.DeepEquals, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256})
+
+	var err error
+	s.provider, err = env.StorageProvider("azure")
+	c.Assert(err, jc.ErrorIsNil)
+	volumeSource := s.volumeSource(c, true)
+	_, err = volumeSource.ListVolumes(s.cloudCallCtx)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The blob storage client uses the same HTTP client as the
+	// management clients.
+	s.storageClient.CheckCallNames(c, "NewClient", "GetContainerReference")
+	s.storageClient.CheckCall(
+		c, 0, "NewClient", storageAccountName, fakeStorageAccountKey,
+		"storage.azurestack.local", azurestorage.DefaultAPIVersion, true, httpClient,
+	)
+}
+
+// senderTransport is an http.RoundTripper that sends requests
+// using an autorest.Sender.
+type senderTransport struct {
+	sender autorest.Sender
+}
+
+// RoundTrip is part of the http.RoundTripper interface.
+func (t senderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.sender.Do(req)
+}
+
 func (s *storageSuite) TestListVolumesErrors(c *gc.C) {
 	volumeSource := s.volumeSource(c, false)
 	sender := mocks.NewSender()
@@ -643,7 +705,7 @@ func (s *storageSuite) TestDescribeVolumesLegacy(c *gc.C) {
 	s.storageClient.CheckCallNames(c, "NewClient", "GetContainerReference")
 	s.storageClient.CheckCall(
 		c, 0, "NewClient", storageAccountName, fakeStorageAccountKey,
-		"storage.azurestack.local", azurestorage.DefaultAPIVersion, true,
+		"storage.azurestack.local", azurestorage.DefaultAPIVersion, true, (*http.Client)(nil),
 	)
 	c.Assert(results, gc.HasLen, 4)
 	c.Assert(results[:3], jc.DeepEquals, []storage.DescribeVolumesResult{{
@@ -4,7 +4,9 @@
 package azure
 
 import (
+	"crypto/tls"
 	"math/rand"
+	"net"
 	"net/http"
 	"time"
 
@@ -59,6 +61,26 @@ func isNotFoundResponse(resp autorest.Response) bool {
 	return false
 }
 
+// isTransientResponse reports whether the response indicates a failure
+// that may succeed if the request is retried: the service being busy
+// or unavailable, or the request being throttled.
+func isTransientResponse(resp autorest.Response) bool {
+	if resp.Response == nil {
+		return false
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= http.StatusInternalServerError
+}
+
+// transientResponseError returns err, wrapped as a transientError if
+// the response indicates that the request may succeed if retried.
+func transientResponseError(resp autorest.Response, err error) error {
+	if err != nil && isTransientResponse(resp) {
+		return transientError{err}
+	}
+	return err
+}
+
 // collectAPIVersions returns a map of the latest API version for each
 // possible resource type. This is needed to use the Azure Resource
 // Management API, because the API version requested must match the
@@ -94,3 +116,28 @@ func collectAPIVersions(ctx context.ProviderCallContext, client resources.Provid
 	}
 	return result, nil
 }
+
+// newSecureSender returns an HTTP client, usable as an autorest.Sender,
+// that talks to Azure using the given TLS configuration. Connection
+// setup fails with a TLS handshake error if the endpoint cannot
+// negotiate an acceptable version and cipher suite.
+//
+// The transport otherwise matches http.DefaultTransport, so proxy
+// settings are honoured and connections are kept alive between
+// requests.
+func newSecureSender(tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+			TLSClientConfig:       tlsConfig,
+		},
+	}
+}
@@ -181,13 +181,15 @@ var ebsConfigFields = schema.Fields{
 	EBS_Encrypted: schema.Bool(),
 }
 
+var ebsConfigDefaults = schema.Defaults{
+	EBS_VolumeType: volumeAliasSSD,
+	EBS_IOPS:       schema.Omit,
+	EBS_Encrypted:  false,
+}
+
 var ebsConfigChecker = schema.FieldMap(
 	ebsConfigFields,
-	schema.Defaults{
-		EBS_VolumeType: volumeAliasSSD,
-		EBS_IOPS:       schema.Omit,
-		EBS_Encrypted:  false,
-	},
+	ebsConfigDefaults,
 )
 
 type ebsConfig struct {
@@ -255,6 +257,17 @@ func (*ebsProvider) Releasable() bool {
 	return true
 }
 
+// DefaultPoolAttributes is defined on the storage.PoolDefaulter interface.
+func (e *ebsProvider) DefaultPoolAttributes() map[string]interface{} {
+	attrs := make(map[string]interface{})
+	for k, v := range ebsConfigDefaults {
+		if v != schema.Omit {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
+
 // DefaultPools is defined on the Provider interface.
 func (e *ebsProvider) DefaultPools() []*storage.Config {
 	ssdPool, _ := storage.NewConfig("ebs-ssd", EBS_ProviderType, map[string]interface{}{
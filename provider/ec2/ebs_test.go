@@ -108,6 +108,15 @@ func (s *ebsSuite) TestSupports(c *gc.C) {
 	c.Assert(p.Supports(storage.StorageKindFilesystem), jc.IsFalse)
 }
 
+func (s *ebsSuite) TestDefaultPoolAttributes(c *gc.C) {
+	defaulter, ok := s.ebsProvider(c).(storage.PoolDefaulter)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(defaulter.DefaultPoolAttributes(), jc.DeepEquals, map[string]interface{}{
+		"volume-type": "ssd",
+		"encrypted":   false,
+	})
+}
+
 func (s *ebsSuite) volumeSource(c *gc.C, cfg *storage.Config) storage.VolumeSource {
 	p := s.ebsProvider(c)
 	vs, err := p.VolumeSource(cfg)
@@ -80,12 +80,14 @@ var lxdStorageConfigFields = schema.Fields{
 	attrLXDStoragePool: schema.String(),
 }
 
+var lxdStorageConfigDefaults = schema.Defaults{
+	attrLXDStorageDriver: "dir",
+	attrLXDStoragePool:   schema.Omit,
+}
+
 var lxdStorageConfigChecker = schema.FieldMap(
 	lxdStorageConfigFields,
-	schema.Defaults{
-		attrLXDStorageDriver: "dir",
-		attrLXDStoragePool:   schema.Omit,
-	},
+	lxdStorageConfigDefaults,
 )
 
 type lxdStorageConfig struct {
@@ -159,6 +161,17 @@ func (*lxdStorageProvider) Releasable() bool {
 	return true
 }
 
+// DefaultPoolAttributes is part of the storage.PoolDefaulter interface.
+func (e *lxdStorageProvider) DefaultPoolAttributes() map[string]interface{} {
+	attrs := make(map[string]interface{})
+	for k, v := range lxdStorageConfigDefaults {
+		if v != schema.Omit {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
+
 // DefaultPools is part of the Provider interface.
 func (e *lxdStorageProvider) DefaultPools() []*storage.Config {
 	zfsPool, _ := storage.NewConfig("lxd-zfs", lxdStorageProviderType, map[string]interface{}{
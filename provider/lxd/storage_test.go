@@ -87,6 +87,14 @@ func (s *storageSuite) TestStorageDefaultPoolsDriverNotSupported(c *gc.C) {
 	s.Stub.CheckCallNames(c, "CreatePool", "GetStoragePool", "CreatePool")
 }
 
+func (s *storageSuite) TestDefaultPoolAttributes(c *gc.C) {
+	defaulter, ok := s.provider.(storage.PoolDefaulter)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(defaulter.DefaultPoolAttributes(), jc.DeepEquals, map[string]interface{}{
+		"driver": "dir",
+	})
+}
+
 func (s *storageSuite) TestVolumeSource(c *gc.C) {
 	_, err := s.provider.VolumeSource(nil)
 	c.Assert(err, gc.ErrorMatches, "volumes not supported")
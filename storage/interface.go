@@ -216,6 +216,15 @@ type VolumeImporter interface {
 	) (VolumeInfo, error)
 }
 
+// PoolDefaulter provides an interface for obtaining the default
+// attributes of new storage pools for a provider.
+type PoolDefaulter interface {
+	// DefaultPoolAttributes returns the attributes that a new pool
+	// for the provider should have, unless they are overridden by
+	// the user.
+	DefaultPoolAttributes() map[string]interface{}
+}
+
 // VolumeParams is a fully specified set of parameters for volume creation,
 // derived from one or more of user-specified storage constraints, a
 // storage pool definition, and charm storage metadata.
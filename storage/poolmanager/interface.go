@@ -16,6 +16,11 @@ type PoolManager interface {
 	// Create makes a new pool with the specified configuration and persists it to state.
 	Create(name string, providerType storage.ProviderType, attrs map[string]interface{}) (*storage.Config, error)
 
+	// Validate returns the configuration a pool with the specified
+	// attributes would have, merged with the provider's defaults and
+	// validated by the provider, without persisting it to state.
+	Validate(name string, providerType storage.ProviderType, attrs map[string]interface{}) (*storage.Config, error)
+
 	// Delete removes the pool with name from state.
 	Delete(name string) error
 
@@ -41,6 +41,22 @@ func globalKey(name string) string {
 
 // Create is defined on PoolManager interface.
 func (pm *poolManager) Create(name string, providerType storage.ProviderType, attrs map[string]interface{}) (*storage.Config, error) {
+	cfg, err := pm.Validate(name, providerType, attrs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	poolAttrs := cfg.Attrs()
+	poolAttrs[Name] = name
+	poolAttrs[Type] = string(providerType)
+	if err := pm.settings.CreateSettings(globalKey(name), poolAttrs); err != nil {
+		return nil, errors.Annotatef(err, "creating pool %q", name)
+	}
+	return cfg, nil
+}
+
+// Validate is defined on PoolManager interface.
+func (pm *poolManager) Validate(name string, providerType storage.ProviderType, attrs map[string]interface{}) (*storage.Config, error) {
 	if name == "" {
 		return nil, MissingNameError
 	}
@@ -56,17 +72,30 @@ func (pm *poolManager) Create(name string, providerType storage.ProviderType, at
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if defaulter, ok := p.(storage.PoolDefaulter); ok {
+		cfg, err = storage.NewConfig(name, providerType, withDefaults(attrs, defaulter))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	if err := provider.ValidateConfig(p, cfg); err != nil {
 		return nil, errors.Annotate(err, "validating storage provider config")
 	}
+	return cfg, nil
+}
 
-	poolAttrs := cfg.Attrs()
-	poolAttrs[Name] = name
-	poolAttrs[Type] = string(providerType)
-	if err := pm.settings.CreateSettings(globalKey(name), poolAttrs); err != nil {
-		return nil, errors.Annotatef(err, "creating pool %q", name)
+// withDefaults returns the given pool attributes, merged over the
+// default pool attributes of the provider. User-specified attributes
+// take precedence.
+func withDefaults(attrs map[string]interface{}, defaulter storage.PoolDefaulter) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for k, v := range defaulter.DefaultPoolAttributes() {
+		merged[k] = v
 	}
-	return cfg, nil
+	for k, v := range attrs {
+		merged[k] = v
+	}
+	return merged
 }
 
 // Delete is defined on PoolManager interface.
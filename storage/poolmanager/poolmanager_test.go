@@ -103,6 +103,20 @@ func (s *poolSuite) TestCreate(c *gc.C) {
 	c.Assert(p.Provider(), gc.Equals, storage.ProviderType("loop"))
 }
 
+func (s *poolSuite) TestCreateProviderDefaults(c *gc.C) {
+	s.registry.Providers["defaulted"] = &defaultingStorageProvider{
+		defaults: map[string]interface{}{"foo": "baz", "volume-type": "ssd"},
+	}
+	_, err := s.poolManager.Create("testpool", "defaulted", map[string]interface{}{"foo": "bar"})
+	c.Assert(err, jc.ErrorIsNil)
+	p, err := s.poolManager.Get("testpool")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(p.Attrs(), jc.DeepEquals, map[string]interface{}{
+		"foo":         "bar",
+		"volume-type": "ssd",
+	})
+}
+
 func (s *poolSuite) TestCreateAlreadyExists(c *gc.C) {
 	_, err := s.poolManager.Create("testpool", storage.ProviderType("loop"), map[string]interface{}{"foo": "bar"})
 	c.Assert(err, jc.ErrorIsNil)
@@ -130,6 +144,31 @@ func (s *poolSuite) TestCreateInvalidConfig(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "validating storage provider config: no good")
 }
 
+func (s *poolSuite) TestValidate(c *gc.C) {
+	s.registry.Providers["defaulted"] = &defaultingStorageProvider{
+		defaults: map[string]interface{}{"foo": "baz", "volume-type": "ssd"},
+	}
+	cfg, err := s.poolManager.Validate("testpool", "defaulted", map[string]interface{}{"foo": "bar"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.Name(), gc.Equals, "testpool")
+	c.Assert(cfg.Attrs(), jc.DeepEquals, map[string]interface{}{
+		"foo":         "bar",
+		"volume-type": "ssd",
+	})
+	_, err = s.poolManager.Get("testpool")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *poolSuite) TestValidateInvalidConfig(c *gc.C) {
+	s.registry.Providers["invalid"] = &dummystorage.StorageProvider{
+		ValidateConfigFunc: func(*storage.Config) error {
+			return errors.New("no good")
+		},
+	}
+	_, err := s.poolManager.Validate("testpool", "invalid", nil)
+	c.Assert(err, gc.ErrorMatches, "validating storage provider config: no good")
+}
+
 func (s *poolSuite) TestDelete(c *gc.C) {
 	s.createSettings(c)
 	err := s.poolManager.Delete("testpool")
@@ -140,3 +179,12 @@ func (s *poolSuite) TestDelete(c *gc.C) {
 	err = s.poolManager.Delete("testpool")
 	c.Assert(err, jc.ErrorIsNil)
 }
+
+type defaultingStorageProvider struct {
+	dummystorage.StorageProvider
+	defaults map[string]interface{}
+}
+
+func (p *defaultingStorageProvider) DefaultPoolAttributes() map[string]interface{} {
+	return p.defaults
+}
@@ -135,6 +135,11 @@ func (w *stubWorker) Wait() error {
 	return w.stub.NextErr()
 }
 
+func (w *stubWorker) Report() map[string]interface{} {
+	w.stub.MethodCall(w, "Report")
+	return map[string]interface{}{"responsible": true}
+}
+
 var errClaimDenied = errors.Trace(lease.ErrClaimDenied)
 
 type fakeClock struct {
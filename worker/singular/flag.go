@@ -99,6 +99,14 @@ func (flag *FlagWorker) Check() bool {
 	return flag.valid
 }
 
+// Report shows up in the dependency engine report.
+func (flag *FlagWorker) Report() map[string]interface{} {
+	return map[string]interface{}{
+		"responsible":    flag.valid,
+		"lease-duration": flag.config.Duration.String(),
+	}
+}
+
 // run invokes a suitable runFunc, depending on the value of .valid.
 func (flag *FlagWorker) run() error {
 	runFunc := waitVacant
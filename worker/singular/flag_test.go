@@ -76,6 +76,28 @@ func (s *FlagSuite) TestClaimSuccess(c *gc.C) {
 	fix.CheckClaims(c, 1)
 }
 
+func (s *FlagSuite) TestReport(c *gc.C) {
+	fix := newFixture(c, nil)
+	fix.Run(c, func(flag *singular.FlagWorker, _ *testclock.Clock, _ func()) {
+		c.Check(flag.Report(), jc.DeepEquals, map[string]interface{}{
+			"responsible":    true,
+			"lease-duration": "1m0s",
+		})
+	})
+	fix.CheckClaims(c, 1)
+}
+
+func (s *FlagSuite) TestReportNotResponsible(c *gc.C) {
+	fix := newFixture(c, errClaimDenied)
+	fix.Run(c, func(flag *singular.FlagWorker, _ *testclock.Clock, _ func()) {
+		c.Check(flag.Report(), jc.DeepEquals, map[string]interface{}{
+			"responsible":    false,
+			"lease-duration": "1m0s",
+		})
+	})
+	fix.CheckClaimWait(c)
+}
+
 func (s *FlagSuite) TestClaimSuccessThenFailure(c *gc.C) {
 	fix := newFixture(c, nil, errClaimDenied)
 	fix.Run(c, func(flag *singular.FlagWorker, clock *testclock.Clock, unblock func()) {
@@ -70,6 +70,16 @@ func (w wrappedWorker) Wait() error {
 	return err
 }
 
+// Report shows up in the dependency engine report.
+func (w wrappedWorker) Report() map[string]interface{} {
+	if r, ok := w.Worker.(interface {
+		Report() map[string]interface{}
+	}); ok {
+		return r.Report()
+	}
+	return nil
+}
+
 // Manifold returns a dependency.Manifold that will run a FlagWorker and
 // expose it to clients as a engine.Flag resource.
 func Manifold(config ManifoldConfig) dependency.Manifold {
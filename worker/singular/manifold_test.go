@@ -200,3 +200,33 @@ func (s *ManifoldSuite) TestWorkerBouncesOnRefresh(c *gc.C) {
 	c.Check(err, jc.ErrorIsNil)
 	c.Check(worker.Wait(), gc.Equals, dependency.ErrBounce)
 }
+
+func (s *ManifoldSuite) TestWorkerReport(c *gc.C) {
+	var stub testing.Stub
+	expectWorker := newStubWorker(&stub)
+	manifold := singular.Manifold(singular.ManifoldConfig{
+		ClockName:     "clock",
+		APICallerName: "api-caller",
+		NewFacade: func(base.APICaller, names.MachineTag, names.Tag) (singular.Facade, error) {
+			return &fakeFacade{}, nil
+		},
+		NewWorker: func(_ singular.FlagConfig) (worker.Worker, error) {
+			return expectWorker, nil
+		},
+	})
+	context := dt.StubContext(nil, map[string]interface{}{
+		"clock":      &fakeClock{},
+		"api-caller": &fakeAPICaller{},
+	})
+
+	w, err := manifold.Start(context)
+	c.Assert(err, jc.ErrorIsNil)
+	reporter, ok := w.(interface {
+		Report() map[string]interface{}
+	})
+	c.Assert(ok, jc.IsTrue)
+	c.Check(reporter.Report(), jc.DeepEquals, map[string]interface{}{
+		"responsible": true,
+	})
+	stub.CheckCallNames(c, "Report")
+}